@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"repository-un/internal/handlers"
+)
+
+// gcUsage adalah bantuan subcommand gc
+const gcUsage = `Pemakaian: server [-config file] gc [-dry-run] [-min-age durasi]
+
+Mencocokkan isi storage (uploads/ dan uploads/split/) dengan database:
+file yang tidak dirujuk dokumen, versi, blob atau sesi upload dihapus,
+file yang dirujuk database tetapi tidak ada dilaporkan (exit code 1).
+
+Opsi:`
+
+// runGC menjalankan subcommand "gc"
+func runGC(args []string) {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "hanya tampilkan, jangan hapus apa pun")
+	minAge := fs.Duration("min-age", time.Hour, "lewati file yatim yang lebih muda dari durasi ini")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, gcUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	report, err := handlers.CollectGarbage(context.Background(), handlers.GCOptions{
+		DryRun: *dryRun,
+		MinAge: *minAge,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cutoff := time.Now().Add(-*minAge)
+	for _, obj := range report.Orphans {
+		switch {
+		case obj.ModTime.After(cutoff):
+			fmt.Printf("baru    %s\n", obj.Key)
+		case *dryRun:
+			fmt.Printf("yatim   %s\n", obj.Key)
+		default:
+			fmt.Printf("hapus   %s\n", obj.Key)
+		}
+	}
+	for _, m := range report.Missing {
+		fmt.Printf("hilang  %s (dokumen %s versi %d)\n", m.Key, m.DocumentID, m.VersionNumber)
+	}
+
+	fmt.Printf("\n%d file diperiksa, %d yatim (%d dihapus, %d dilewati karena baru), %d hilang\n",
+		report.Scanned, len(report.Orphans), report.Removed, report.Skipped, len(report.Missing))
+	fmt.Printf("%d ref_count blob dibetulkan, %d blob tanpa referensi dihapus\n",
+		report.RefCountsFixed, report.BlobsReleased)
+	if *dryRun {
+		fmt.Println("Dry run: tidak ada yang diubah")
+	}
+
+	if len(report.Missing) > 0 {
+		os.Exit(1)
+	}
+}
@@ -9,80 +9,134 @@ Struktur folder:
     - config/       : Konfigurasi (database, dll)
     - handlers/     : HTTP handlers untuk setiap endpoint
     - middleware/   : Middleware (auth, cors, dll)
+    - router/       : Routing berdasarkan method & path parameter
     - models/       : Struktur data (User, Document, dll)
     - utils/        : Fungsi utilitas (PDF processing, dll)
   - migrations/     : SQL migration files
   - uploads/        : File yang diupload
 
 Cara menjalankan:
-  go run cmd/server/main.go
+  go run ./cmd/server
+
+Migration database:
+  go run ./cmd/server migrate up|down|status|force
+
+Bersihkan file yatim dan cek file yang hilang:
+  go run ./cmd/server gc [-dry-run]
 
 Atau build dan run:
-  go build -o server.exe cmd/server/main.go
+  go build -o server.exe ./cmd/server
   ./server.exe
 */
 
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"net/http"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"repository-un/internal/config"
 	"repository-un/internal/handlers"
-	"repository-un/internal/middleware"
+	"repository-un/internal/logging"
+	"repository-un/internal/server"
 )
 
 func main() {
+	// Baca konfigurasi dari file (opsional) dan environment
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "file konfigurasi YAML/TOML")
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		log.Fatal(err)
+	}
+
+	// Log terstruktur (LOG_LEVEL, LOG_FORMAT), termasuk access log setiap request
+	config.SetupLogger()
+
 	// Koneksi ke database
 	config.ConnectDB()
 
-	// ============================================
-	// ROUTES
-	// ============================================
+	// Subcommand "migrate" hanya mengelola skema lalu selesai
+	if flag.Arg(0) == "migrate" {
+		runMigrate(flag.Args()[1:])
+		config.CloseDB()
+		return
+	}
 
-	// --- Auth Routes (Public) ---
-	// Login dan register tidak perlu token
-	http.HandleFunc("/api/auth/login", handlers.LoginHandler)
-	http.HandleFunc("/api/auth/register", handlers.RegisterHandler)
-	http.HandleFunc("/api/auth/me", middleware.AuthMiddleware(handlers.GetMeHandler))
+	// Jalankan migration yang tertunda (atau berhenti, lihat DB_AUTO_MIGRATE)
+	config.MigrateDB()
 
-	// --- User Routes (Admin Only) ---
-	// Hanya admin yang bisa mengelola user
-	http.HandleFunc("/api/users", middleware.AdminMiddleware(handlers.UsersHandler))
-	http.HandleFunc("/api/users/", middleware.AdminMiddleware(handlers.UserByIdHandler))
+	// Siapkan storage file (lokal atau S3)
+	config.ConnectStorage()
 
-	// --- Document Routes ---
-	// CRUD dokumen
-	http.HandleFunc("/uploads", handlers.UploadHandler)
-	http.HandleFunc("/api/documents", handlers.DocumentsHandler)
-	http.HandleFunc("/api/documents/", handlers.DocumentByIdHandler)
-	http.HandleFunc("/api/documents/pages/", handlers.DocumentPagesHandler)
+	// Subcommand "gc" mencocokkan storage dengan database lalu selesai
+	if flag.Arg(0) == "gc" {
+		runGC(flag.Args()[1:])
+		config.CloseDB()
+		return
+	}
 
-	// --- File Routes ---
-	// Download dan preview file
-	http.HandleFunc("/download/", handlers.DownloadHandler)
-	http.HandleFunc("/preview/split/", handlers.PreviewSplitHandler)
+	// Pemindai malware file upload (SCANNER_DRIVER)
+	config.SetupScanner()
 
-	// Static file server untuk split PDF
-	http.Handle("/split/", http.StripPrefix("/split/", http.FileServer(http.Dir("uploads/split"))))
+	// Worker background untuk pemrosesan PDF
+	handlers.RegisterJobs()
+	config.StartJobWorker()
+
+	// ============================================
+	// ROUTES
+	// ============================================
+
+	// Daftar lengkap route ada di internal/handlers/routes.go.
+	// Path parameter bertipe (UUID, angka), 404/405 dijawab JSON dan
+	// middleware CORS/autentikasi dipasang per grup route.
+	routes := handlers.Routes()
 
 	// ============================================
 	// START SERVER
 	// ============================================
-	fmt.Println("========================================")
-	fmt.Println("  Repository UN - Backend Server")
-	fmt.Println("========================================")
-	fmt.Println("Server running at http://localhost:8080")
-	fmt.Println("")
-	fmt.Println("Endpoints:")
-	fmt.Println("  POST /api/auth/login     - Login")
-	fmt.Println("  POST /api/auth/register  - Register")
-	fmt.Println("  GET  /api/auth/me        - Get current user")
-	fmt.Println("  GET  /api/users          - List users (admin)")
-	fmt.Println("  GET  /api/documents      - List documents")
-	fmt.Println("  POST /api/documents      - Create document")
-	fmt.Println("")
-
-	http.ListenAndServe(":8080", nil)
+	// Banner hanya untuk log text, log JSON dibaca oleh log collector
+	if config.App.Log.Format == logging.FormatText {
+		fmt.Println("========================================")
+		fmt.Println("  Repository UN - Backend Server")
+		fmt.Println("========================================")
+		fmt.Println("")
+		fmt.Println("Endpoints:")
+		fmt.Println("  POST /api/auth/login     - Login")
+		fmt.Println("  POST /api/auth/register  - Register")
+		fmt.Println("  POST /api/auth/refresh   - Refresh access token")
+		fmt.Println("  POST /api/auth/logout    - Logout")
+		fmt.Println("  GET  /api/auth/me        - Get current user")
+		fmt.Println("  GET  /.well-known/jwks.json - JWT public keys")
+		fmt.Println("  GET  /api/users          - List users (admin)")
+		fmt.Println("  GET  /api/documents      - List documents")
+		fmt.Println("  POST /api/documents      - Create document")
+		fmt.Println("")
+	}
+	slog.Info("Server running", "addr", config.App.Server.Addr)
+
+	// Berhenti dengan rapi saat Ctrl+C / SIGTERM: request yang sedang
+	// berjalan (upload) diselesaikan, lalu job background, lalu database ditutup
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runErr := server.Run(ctx, routes, config.App.Server)
+	if runErr != nil {
+		slog.Error("Server error", "error", runErr)
+	}
+
+	slog.Info("Menunggu job background selesai...")
+	config.StopJobWorker()
+	config.CloseDB()
+
+	if runErr != nil {
+		os.Exit(1)
+	}
+	slog.Info("Server stopped")
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"repository-un/internal/config"
+)
+
+// migrateUsage adalah bantuan subcommand migrate
+const migrateUsage = `Pemakaian: server [-config file] migrate <perintah>
+
+Perintah:
+  up              Jalankan semua migration yang belum dijalankan
+  down [n]        Rollback n migration terakhir (default 1)
+  status          Tampilkan status semua migration
+  force <versi>   Tandai migration sampai <versi> sudah dijalankan tanpa
+                  menjalankan SQL-nya (untuk database lama)`
+
+// runMigrate menjalankan subcommand "migrate"
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	m := config.Migrator()
+
+	switch args[0] {
+	case "up":
+		ran, err := m.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, mig := range ran {
+			fmt.Printf("up   %03d_%s\n", mig.Version, mig.Name)
+		}
+		if len(ran) == 0 {
+			fmt.Println("Tidak ada migration yang tertunda")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatal("Jumlah langkah tidak valid: ", args[1])
+			}
+			steps = n
+		}
+
+		rolledBack, err := m.Down(ctx, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, mig := range rolledBack {
+			fmt.Printf("down %03d_%s\n", mig.Version, mig.Name)
+		}
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				state += " (checksum berbeda!)"
+			}
+			fmt.Printf("%03d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			log.Fatal("force butuh nomor versi")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatal("Versi tidak valid: ", args[1])
+		}
+		if err := m.Force(ctx, version); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Migration sampai versi %03d ditandai sudah dijalankan\n", version)
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
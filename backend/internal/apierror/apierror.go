@@ -0,0 +1,155 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"repository-un/internal/requestid"
+)
+
+// Text adalah pesan dalam bahasa Indonesia dan Inggris
+type Text struct {
+	ID string
+	EN string
+}
+
+// Textf membuat Text dari dua format pesan dengan argumen yang sama
+func Textf(id, en string, args ...interface{}) Text {
+	return Text{ID: fmt.Sprintf(id, args...), EN: fmt.Sprintf(en, args...)}
+}
+
+// In mengembalikan pesan untuk bahasa lang ("id" atau "en")
+func (t Text) In(lang string) string {
+	if lang == LangEN && t.EN != "" {
+		return t.EN
+	}
+	return t.ID
+}
+
+// FieldError adalah kesalahan validasi pada satu field request
+type FieldError struct {
+	Field   string
+	Code    string
+	Message Text
+}
+
+// Error adalah error yang dikirim ke client. Code bisa dibaca mesin dan
+// tidak berubah, Message ditampilkan ke user sesuai Accept-Language.
+type Error struct {
+	Status  int
+	Code    string
+	Message Text
+	Fields  []FieldError
+
+	// Meta berisi data tambahan yang bisa dibaca mesin, misalnya ID
+	// dokumen yang menyebabkan konflik
+	Meta map[string]interface{}
+}
+
+// New membuat error API dengan pesan Indonesia (id) dan Inggris (en)
+func New(status int, code, id, en string) *Error {
+	return &Error{Status: status, Code: code, Message: Text{ID: id, EN: en}}
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message.ID
+}
+
+// WithFields mengembalikan salinan error dengan detail field
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithMessage mengembalikan salinan error dengan pesan lain
+func (e *Error) WithMessage(message Text) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// WithMeta mengembalikan salinan error dengan data tambahan
+func (e *Error) WithMeta(meta map[string]interface{}) *Error {
+	c := *e
+	c.Meta = meta
+	return &c
+}
+
+// Error umum yang dipakai di semua handler
+var (
+	BadRequest       = New(http.StatusBadRequest, "invalid_request_body", "Request body tidak valid", "Invalid request body")
+	ValidationFailed = New(http.StatusBadRequest, "validation_failed", "Data tidak valid", "Validation failed")
+	Unauthorized     = New(http.StatusUnauthorized, "unauthorized", "Silakan login terlebih dahulu", "Authentication required")
+	InvalidToken     = New(http.StatusUnauthorized, "invalid_token", "Token tidak valid atau sudah kedaluwarsa", "Invalid or expired token")
+	Forbidden        = New(http.StatusForbidden, "forbidden", "Anda tidak memiliki akses", "Access denied")
+	NotFound         = New(http.StatusNotFound, "not_found", "Endpoint tidak ditemukan", "Endpoint not found")
+	MethodNotAllowed = New(http.StatusMethodNotAllowed, "method_not_allowed", "Method tidak didukung", "Method not allowed")
+	Internal         = New(http.StatusInternalServerError, "internal_error", "Terjadi kesalahan pada server", "Internal server error")
+)
+
+// response adalah bentuk JSON error. Field error tetap berupa string
+// pesan agar client lama yang membaca {"error": "..."} tetap berjalan.
+type response struct {
+	Error     string                 `json:"error"`
+	Code      string                 `json:"code"`
+	Status    int                    `json:"status"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Details   []fieldDetail          `json:"details,omitempty"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+}
+
+type fieldDetail struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Write menulis err sebagai JSON. Error selain *Error dicatat ke log dan
+// dikirim sebagai internal_error agar pesan internal tidak bocor ke client.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		slog.ErrorContext(r.Context(), "Error tidak tertangani",
+			"method", r.Method, "path", r.URL.Path, "error", err)
+		e = Internal
+	}
+
+	lang := Language(r)
+	resp := response{
+		Error:     e.Message.In(lang),
+		Code:      e.Code,
+		Status:    e.Status,
+		RequestID: requestid.FromContext(r.Context()),
+		Meta:      e.Meta,
+	}
+	for _, f := range e.Fields {
+		resp.Details = append(resp.Details, fieldDetail{Field: f.Field, Code: f.Code, Message: f.Message.In(lang)})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Language", lang)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Field membuat kesalahan validasi untuk satu field. Format pesan id dan
+// en memakai argumen yang sama.
+func Field(field, code, id, en string, args ...interface{}) FieldError {
+	return FieldError{Field: field, Code: code, Message: Textf(id, en, args...)}
+}
+
+// Required adalah kesalahan field wajib yang kosong
+func Required(field string) FieldError {
+	return Field(field, "required", "wajib diisi", "is required")
+}
+
+// Invalid adalah kesalahan field yang nilainya tidak valid
+func Invalid(field string) FieldError {
+	return Field(field, "invalid", "tidak valid", "is invalid")
+}
@@ -0,0 +1,48 @@
+package apierror
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bahasa pesan error yang didukung
+const (
+	LangID = "id"
+	LangEN = "en"
+)
+
+// Language memilih bahasa pesan dari header Accept-Language, misalnya
+// "en-US,en;q=0.9,id;q=0.8". Bahasa yang tidak didukung dilewati dan
+// default-nya bahasa Indonesia.
+func Language(r *http.Request) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 && (primary == LangID || primary == LangEN) {
+			candidates = append(candidates, candidate{primary, q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) > 0 {
+		return candidates[0].lang
+	}
+	return LangID
+}
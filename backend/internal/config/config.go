@@ -0,0 +1,349 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository-un/internal/filetype"
+)
+
+// Config berisi seluruh pengaturan server. Nilai diambil berurutan dari
+// default, file konfigurasi (YAML/TOML, opsional) lalu environment variable
+// (tag env), sehingga environment selalu menang.
+type Config struct {
+	// Env adalah "production" atau "development". Pengaturan yang tidak
+	// aman untuk server sungguhan (misalnya JWT secret acak) hanya
+	// diizinkan di development.
+	Env string `yaml:"env" toml:"env" env:"APP_ENV"`
+
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Database DatabaseConfig `yaml:"database" toml:"database"`
+	Storage  StorageConfig  `yaml:"storage" toml:"storage"`
+	Upload   UploadConfig   `yaml:"upload" toml:"upload"`
+	CORS     CORSConfig     `yaml:"cors" toml:"cors"`
+	JWT      JWTConfig      `yaml:"jwt" toml:"jwt"`
+	Preview  PreviewConfig  `yaml:"preview" toml:"preview"`
+	OAI      OAIConfig      `yaml:"oai" toml:"oai"`
+	Jobs     JobsConfig     `yaml:"jobs" toml:"jobs"`
+	Scanner  ScannerConfig  `yaml:"scanner" toml:"scanner"`
+	Metrics  MetricsConfig  `yaml:"metrics" toml:"metrics"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+}
+
+// ServerConfig mengatur HTTP server
+type ServerConfig struct {
+	// Addr adalah alamat listen, contoh ":8080" atau "127.0.0.1:8080"
+	Addr string `yaml:"addr" toml:"addr" env:"SERVER_ADDR"`
+
+	// ReadHeaderTimeout membatasi waktu membaca header request
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+
+	// ReadTimeout membatasi waktu membaca seluruh request termasuk body
+	// (upload file), WriteTimeout membatasi waktu menulis response (download)
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+
+	// IdleTimeout adalah lama koneksi keep-alive menunggu request berikutnya
+	IdleTimeout time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+
+	// ShutdownTimeout adalah batas waktu menunggu request yang sedang
+	// berjalan saat server dihentikan
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+
+	TLS TLSConfig `yaml:"tls" toml:"tls"`
+}
+
+// TLSConfig mengaktifkan HTTPS. Kosong berarti server memakai HTTP biasa
+// (misalnya di belakang reverse proxy). File yang berubah dimuat ulang
+// otomatis atau saat server menerima SIGHUP.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" toml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `yaml:"key_file" toml:"key_file" env:"TLS_KEY_FILE"`
+}
+
+// DatabaseConfig mengatur koneksi PostgreSQL
+type DatabaseConfig struct {
+	// URL adalah DSN PostgreSQL, wajib diisi
+	URL string `yaml:"url" toml:"url" env:"DATABASE_URL"`
+
+	// AutoMigrate menjalankan migration yang tertunda saat server start.
+	// Jika false, server menolak start selama masih ada migration tertunda.
+	AutoMigrate bool `yaml:"auto_migrate" toml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+}
+
+// StorageConfig mengatur tempat penyimpanan file dokumen
+type StorageConfig struct {
+	// Driver adalah "local" atau "s3"
+	Driver string `yaml:"driver" toml:"driver" env:"STORAGE_DRIVER"`
+
+	// LocalDir adalah folder upload untuk driver local
+	LocalDir string `yaml:"local_dir" toml:"local_dir" env:"STORAGE_LOCAL_DIR"`
+
+	S3 S3Config `yaml:"s3" toml:"s3"`
+}
+
+// S3Config mengatur storage S3-compatible (AWS S3, MinIO)
+type S3Config struct {
+	Endpoint        string `yaml:"endpoint" toml:"endpoint" env:"S3_ENDPOINT"`
+	AccessKeyID     string `yaml:"access_key_id" toml:"access_key_id" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" toml:"secret_access_key" env:"S3_SECRET_ACCESS_KEY"`
+	Bucket          string `yaml:"bucket" toml:"bucket" env:"S3_BUCKET"`
+	Region          string `yaml:"region" toml:"region" env:"S3_REGION"`
+	UseSSL          bool   `yaml:"use_ssl" toml:"use_ssl" env:"S3_USE_SSL"`
+}
+
+// UploadConfig mengatur penerimaan file upload
+type UploadConfig struct {
+	// MaxMemory adalah bagian form multipart yang disimpan di memori,
+	// sisanya ditulis ke file sementara
+	MaxMemory ByteSize `yaml:"max_memory" toml:"max_memory" env:"UPLOAD_MAX_MEMORY"`
+
+	// ChunkSize adalah ukuran potongan upload bertahap (resumable),
+	// MaxSize batas ukuran file yang diupload bertahap
+	ChunkSize ByteSize `yaml:"chunk_size" toml:"chunk_size" env:"UPLOAD_CHUNK_SIZE"`
+	MaxSize   ByteSize `yaml:"max_size" toml:"max_size" env:"UPLOAD_MAX_SIZE"`
+
+	// SessionTTL adalah umur sesi upload bertahap sejak potongan terakhir
+	// diterima. Sesi yang kedaluwarsa dihapus beserta potongannya.
+	SessionTTL time.Duration `yaml:"session_ttl" toml:"session_ttl" env:"UPLOAD_SESSION_TTL"`
+
+	// AllowedTypes adalah format file yang boleh diupload: pdf, docx, odt,
+	// epub, image, zip. Format dideteksi dari isi file, bukan ekstensinya.
+	AllowedTypes []string `yaml:"allowed_types" toml:"allowed_types" env:"UPLOAD_ALLOWED_TYPES"`
+
+	// TypeMaxSize adalah ukuran file maksimal per format
+	TypeMaxSize TypeMaxSize `yaml:"type_max_size" toml:"type_max_size"`
+}
+
+// TypeMaxSize mengatur ukuran file maksimal untuk setiap format upload
+type TypeMaxSize struct {
+	PDF   ByteSize `yaml:"pdf" toml:"pdf" env:"UPLOAD_MAX_SIZE_PDF"`
+	DOCX  ByteSize `yaml:"docx" toml:"docx" env:"UPLOAD_MAX_SIZE_DOCX"`
+	ODT   ByteSize `yaml:"odt" toml:"odt" env:"UPLOAD_MAX_SIZE_ODT"`
+	EPUB  ByteSize `yaml:"epub" toml:"epub" env:"UPLOAD_MAX_SIZE_EPUB"`
+	Image ByteSize `yaml:"image" toml:"image" env:"UPLOAD_MAX_SIZE_IMAGE"`
+	ZIP   ByteSize `yaml:"zip" toml:"zip" env:"UPLOAD_MAX_SIZE_ZIP"`
+}
+
+// For mengembalikan ukuran maksimal format name (lihat filetype.Names),
+// 0 jika format tidak dikenal
+func (t TypeMaxSize) For(name string) ByteSize {
+	switch name {
+	case filetype.NamePDF:
+		return t.PDF
+	case filetype.NameDOCX:
+		return t.DOCX
+	case filetype.NameODT:
+		return t.ODT
+	case filetype.NameEPUB:
+		return t.EPUB
+	case filetype.NameImage:
+		return t.Image
+	case filetype.NameZIP:
+		return t.ZIP
+	}
+	return 0
+}
+
+// Allows mengecek apakah format name boleh diupload
+func (u UploadConfig) Allows(name string) bool {
+	return slices.Contains(u.AllowedTypes, name)
+}
+
+// CORSConfig mengatur origin frontend yang boleh mengakses API
+type CORSConfig struct {
+	// AllowedOrigins berisi origin seperti "https://repo.univ.ac.id",
+	// atau "*" untuk semua origin
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+}
+
+// JWTConfig mengatur kunci penandatangan access token
+type JWTConfig struct {
+	// PrivateKeyFile adalah file PEM private key RSA (RS256) atau Ed25519 (EdDSA)
+	PrivateKeyFile string `yaml:"private_key_file" toml:"private_key_file" env:"JWT_PRIVATE_KEY_FILE"`
+
+	// Secret adalah secret HS256, dipakai jika PrivateKeyFile kosong
+	Secret string `yaml:"secret" toml:"secret" env:"JWT_SECRET"`
+
+	// KeyID adalah kid signing key (default diturunkan dari kunci)
+	KeyID string `yaml:"key_id" toml:"key_id" env:"JWT_KEY_ID"`
+
+	// VerificationKeys adalah kunci lama yang masih diterima,
+	// berupa path file PEM atau "kid=path"
+	VerificationKeys []string `yaml:"verification_keys" toml:"verification_keys" env:"JWT_VERIFICATION_KEYS"`
+
+	// PreviousSecrets adalah secret HS256 lama yang masih diterima,
+	// berupa secret atau "kid=secret" (wajib jika secret mengandung "=")
+	PreviousSecrets []string `yaml:"previous_secrets" toml:"previous_secrets" env:"JWT_PREVIOUS_SECRETS"`
+}
+
+// PreviewConfig mengatur render halaman PDF menjadi gambar preview
+type PreviewConfig struct {
+	// Pdftoppm adalah path program pdftoppm (poppler-utils)
+	Pdftoppm string `yaml:"pdftoppm" toml:"pdftoppm" env:"PDFTOPPM_PATH"`
+
+	// ImageFormat adalah "jpg" atau "png"
+	ImageFormat string `yaml:"image_format" toml:"image_format" env:"PREVIEW_IMAGE_FORMAT"`
+}
+
+// OAIConfig berisi identitas repository untuk endpoint OAI-PMH
+type OAIConfig struct {
+	// RepositoryName ditampilkan pada verb Identify
+	RepositoryName string `yaml:"repository_name" toml:"repository_name" env:"OAI_REPOSITORY_NAME"`
+
+	// RepositoryIdentifier adalah nama domain untuk identifier
+	// oai:<RepositoryIdentifier>:<id dokumen>
+	RepositoryIdentifier string `yaml:"repository_id" toml:"repository_id" env:"OAI_REPOSITORY_ID"`
+
+	// AdminEmail adalah kontak pengelola repository
+	AdminEmail string `yaml:"admin_email" toml:"admin_email" env:"OAI_ADMIN_EMAIL"`
+
+	// BaseURL adalah URL publik server. Kosong berarti diambil dari request.
+	BaseURL string `yaml:"base_url" toml:"base_url" env:"OAI_BASE_URL"`
+}
+
+// JobsConfig mengatur worker job background
+type JobsConfig struct {
+	// Workers adalah jumlah job yang dijalankan bersamaan
+	Workers int `yaml:"workers" toml:"workers" env:"JOB_WORKERS"`
+}
+
+// ScannerConfig mengatur pemindaian malware file upload
+type ScannerConfig struct {
+	// Driver adalah "none" (tanpa pemindaian) atau "clamd" (ClamAV)
+	Driver string `yaml:"driver" toml:"driver" env:"SCANNER_DRIVER"`
+
+	// ClamdAddress adalah alamat clamd, contoh "tcp://127.0.0.1:3310"
+	// atau "unix:///run/clamav/clamd.ctl"
+	ClamdAddress string `yaml:"clamd_address" toml:"clamd_address" env:"CLAMD_ADDRESS"`
+
+	// Timeout membatasi waktu memindai satu file
+	Timeout time.Duration `yaml:"timeout" toml:"timeout" env:"SCANNER_TIMEOUT"`
+}
+
+// MetricsConfig mengatur akses endpoint /metrics
+type MetricsConfig struct {
+	// Token adalah bearer token untuk scraper Prometheus. Tanpa token yang
+	// cocok /metrics hanya bisa diakses admin.
+	Token string `yaml:"token" toml:"token" env:"METRICS_TOKEN"`
+}
+
+// LogConfig mengatur log server dan access log
+type LogConfig struct {
+	// Level adalah "debug", "info", "warn" atau "error". Level debug juga
+	// mencatat setiap query database.
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+
+	// Format adalah "text" (mudah dibaca) atau "json" (untuk log collector)
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT"`
+}
+
+// Nilai Config.Env
+const (
+	EnvProduction  = "production"
+	EnvDevelopment = "development"
+)
+
+// Default mengembalikan konfigurasi default. Database.URL sengaja kosong
+// karena wajib diisi.
+func Default() Config {
+	return Config{
+		Env: EnvProduction,
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       10 * time.Minute,
+			WriteTimeout:      10 * time.Minute,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Database: DatabaseConfig{AutoMigrate: true},
+		Storage: StorageConfig{
+			Driver:   "local",
+			LocalDir: "uploads",
+			S3:       S3Config{UseSSL: true},
+		},
+		Upload: UploadConfig{
+			MaxMemory:  10 * MB,
+			ChunkSize:  8 * MB,
+			MaxSize:    2 * GB,
+			SessionTTL: 24 * time.Hour,
+
+			AllowedTypes: slices.Clone(filetype.Names),
+			TypeMaxSize: TypeMaxSize{
+				PDF:   2 * GB,
+				DOCX:  200 * MB,
+				ODT:   200 * MB,
+				EPUB:  200 * MB,
+				Image: 50 * MB,
+				ZIP:   2 * GB,
+			},
+		},
+		CORS: CORSConfig{AllowedOrigins: []string{"*"}},
+		Preview: PreviewConfig{
+			Pdftoppm:    "pdftoppm",
+			ImageFormat: "jpg",
+		},
+		OAI: OAIConfig{
+			RepositoryName:       "Repository UN",
+			RepositoryIdentifier: "repository.local",
+			AdminEmail:           "admin@scholarhub.com",
+		},
+		Jobs: JobsConfig{Workers: 2},
+		Scanner: ScannerConfig{
+			Driver:       "none",
+			ClamdAddress: "tcp://127.0.0.1:3310",
+			Timeout:      5 * time.Minute,
+		},
+		Log: LogConfig{Level: "info", Format: "text"},
+	}
+}
+
+// ByteSize adalah ukuran dalam byte. Bisa ditulis sebagai angka byte
+// atau dengan satuan KB, MB, GB (kelipatan 1024), contoh "10MB".
+type ByteSize int64
+
+// Satuan ByteSize
+const (
+	KB ByteSize = 1 << 10
+	MB ByteSize = 1 << 20
+	GB ByteSize = 1 << 30
+)
+
+// UnmarshalText membaca ukuran seperti "512KB", "10MB" atau "1048576"
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	s := strings.ToUpper(strings.TrimSpace(string(text)))
+	unit := ByteSize(1)
+	for _, u := range []struct {
+		suffix string
+		size   ByteSize
+	}{{"KB", KB}, {"MB", MB}, {"GB", GB}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("ukuran tidak valid: %q", text)
+	}
+	*b = ByteSize(n) * unit
+	return nil
+}
+
+// String menampilkan ukuran dengan satuan terbesar yang pas
+func (b ByteSize) String() string {
+	switch {
+	case b >= GB && b%GB == 0:
+		return fmt.Sprintf("%dGB", b/GB)
+	case b >= MB && b%MB == 0:
+		return fmt.Sprintf("%dMB", b/MB)
+	case b >= KB && b%KB == 0:
+		return fmt.Sprintf("%dKB", b/KB)
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
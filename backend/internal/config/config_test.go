@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"repository-un/internal/filetype"
+	"repository-un/internal/jwtkeys"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestUploadAllows(t *testing.T) {
+	u := Default().Upload
+	for _, name := range filetype.Names {
+		if !u.Allows(name) {
+			t.Errorf("default Allows(%q) = false", name)
+		}
+	}
+
+	env := map[string]string{"UPLOAD_ALLOWED_TYPES": "pdf,image"}
+	c := Default()
+	if problems := applyEnv(reflect.ValueOf(&c).Elem(), lookupMap(env)); problems != nil {
+		t.Fatalf("applyEnv: %v", problems)
+	}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{filetype.NamePDF, true},
+		{filetype.NameImage, true},
+		{filetype.NameDOCX, false},
+		{filetype.NameZIP, false},
+		{"exe", false},
+	}
+	for _, tt := range tests {
+		if got := c.Upload.Allows(tt.name); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTypeMaxSizeFor(t *testing.T) {
+	env := map[string]string{"UPLOAD_MAX_SIZE_PDF": "100MB", "UPLOAD_MAX_SIZE_IMAGE": "512KB"}
+	c := Default()
+	if problems := applyEnv(reflect.ValueOf(&c).Elem(), lookupMap(env)); problems != nil {
+		t.Fatalf("applyEnv: %v", problems)
+	}
+
+	tests := []struct {
+		name string
+		want ByteSize
+	}{
+		{filetype.NamePDF, 100 * MB},
+		{filetype.NameImage, 512 * KB},
+		{filetype.NameDOCX, 200 * MB},
+		{filetype.NameODT, 200 * MB},
+		{filetype.NameEPUB, 200 * MB},
+		{filetype.NameZIP, 2 * GB},
+		{"exe", 0},
+	}
+	for _, tt := range tests {
+		if got := c.Upload.TypeMaxSize.For(tt.name); got != tt.want {
+			t.Errorf("For(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateUploadTypes(t *testing.T) {
+	c := Default()
+	c.Upload.AllowedTypes = []string{"pdf", "exe"}
+	c.Upload.TypeMaxSize.DOCX = 0
+
+	problems := strings.Join(c.Validate(), "\n")
+	for _, want := range []string{`format tidak dikenal: "exe"`, "upload.type_max_size.docx"} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("Validate tidak melaporkan %q:\n%s", want, problems)
+		}
+	}
+}
+
+func lookupMap(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestValidateJWTKeyRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		secret  string
+		keyFile string
+		wantErr bool
+	}{
+		{"production without key", EnvProduction, "", "", true},
+		{"production with secret", EnvProduction, strings.Repeat("s", 32), "", false},
+		{"production with key file", EnvProduction, "", "jwt.pem", false},
+		{"development without key", EnvDevelopment, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.Env = tt.env
+			c.JWT.Secret, c.JWT.PrivateKeyFile = tt.secret, tt.keyFile
+
+			got := strings.Contains(strings.Join(c.Validate(), "\n"), "APP_ENV=development")
+			if got != tt.wantErr {
+				t.Errorf("Validate melaporkan JWT key wajib = %v, want %v", got, tt.wantErr)
+			}
+		})
+	}
+
+	c := Default()
+	c.Env = "dev"
+	if !strings.Contains(strings.Join(c.Validate(), "\n"), `env (APP_ENV) harus`) {
+		t.Error("Validate tidak menolak APP_ENV yang tidak dikenal")
+	}
+}
+
+func TestLoadJWTKeysPreviousSecrets(t *testing.T) {
+	oldSecret := strings.Repeat("o", 30) + "=="
+	old, problems := loadJWTKeys(JWTConfig{Secret: oldSecret, KeyID: "2025-01"})
+	if problems != nil {
+		t.Fatalf("loadJWTKeys: %v", problems)
+	}
+	token, err := old.Sign(jwt.MapClaims{"sub": "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		previous string
+		wantErr  error
+	}{
+		{"with kid", "2025-01=" + oldSecret, nil},
+		{"without kid", "=" + oldSecret, jwtkeys.ErrUnknownKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, problems := loadJWTKeys(JWTConfig{
+				Secret:          strings.Repeat("n", 32),
+				KeyID:           "2025-06",
+				PreviousSecrets: []string{tt.previous},
+			})
+			if problems != nil {
+				t.Fatalf("loadJWTKeys: %v", problems)
+			}
+			_, err := jwt.Parse(token, keys.Keyfunc, jwt.WithValidMethods(keys.Methods()))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifikasi token lama: error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
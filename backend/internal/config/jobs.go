@@ -0,0 +1,25 @@
+package config
+
+import (
+	"context"
+
+	"repository-un/internal/jobs"
+)
+
+// Jobs adalah worker antrian job background global
+var Jobs *jobs.Worker
+
+// StartJobWorker menjalankan worker job background.
+// Jumlah job yang berjalan bersamaan diatur lewat App.Jobs.Workers.
+// Harus dipanggil setelah ConnectDB dan setelah handler job didaftarkan.
+func StartJobWorker() {
+	Jobs = jobs.NewWorker(DB, App.Jobs.Workers)
+	Jobs.Start(context.Background())
+}
+
+// StopJobWorker menunggu job yang sedang berjalan selesai
+func StopJobWorker() {
+	if Jobs != nil {
+		Jobs.Stop()
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"repository-un/internal/jwtkeys"
+)
+
+// JWTKeys adalah kunci untuk membuat dan memverifikasi access token,
+// diisi oleh Load
+var JWTKeys *jwtkeys.KeySet
+
+// loadJWTKeys membuat key set dari pengaturan JWT. Signing key diambil dari
+// PrivateKeyFile, lalu Secret. Tanpa keduanya dibuat secret acak, sehingga
+// token tidak berlaku lagi setiap server restart (hanya diizinkan oleh
+// Validate untuk Env development).
+// Mengembalikan daftar masalah jika ada kunci yang tidak valid.
+func loadJWTKeys(c JWTConfig) (*jwtkeys.KeySet, []string) {
+	var problems []string
+
+	var signing *jwtkeys.Key
+	var err error
+	switch {
+	case c.PrivateKeyFile != "":
+		signing, err = jwtkeys.LoadPEMFile(c.KeyID, c.PrivateKeyFile)
+		if err == nil && !signing.CanSign() {
+			err = fmt.Errorf("%s bukan private key", c.PrivateKeyFile)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("jwt.private_key_file (JWT_PRIVATE_KEY_FILE): %v", err))
+		}
+	case c.Secret != "":
+		// Panjang secret sudah dicek di Validate
+		signing, _ = jwtkeys.NewSecretKey(c.KeyID, []byte(c.Secret))
+	default:
+		slog.Warn("JWT_SECRET tidak di-set, memakai secret acak (token hilang saat restart)")
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			problems = append(problems, fmt.Sprintf("gagal membuat JWT secret: %v", err))
+			break
+		}
+		signing, _ = jwtkeys.NewSecretKey(c.KeyID, secret)
+	}
+
+	var verification []*jwtkeys.Key
+	for _, entry := range c.VerificationKeys {
+		id, path := splitKeyID(entry)
+		k, err := jwtkeys.LoadPEMFile(id, path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("jwt.verification_keys (JWT_VERIFICATION_KEYS): %v", err))
+			continue
+		}
+		verification = append(verification, k)
+	}
+	for i, entry := range c.PreviousSecrets {
+		id, secret := splitKeyID(entry)
+		k, err := jwtkeys.NewSecretKey(id, []byte(secret))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("jwt.previous_secrets (JWT_PREVIOUS_SECRETS) ke-%d: %v", i+1, err))
+			continue
+		}
+		verification = append(verification, k)
+	}
+
+	if signing == nil || len(problems) > 0 {
+		return nil, problems
+	}
+
+	keys, err := jwtkeys.NewKeySet(signing, verification...)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("jwt: %v", err)}
+	}
+	return keys, nil
+}
+
+// splitList memecah daftar yang dipisah koma dan membuang entri kosong
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitKeyID memecah entri "kid=nilai". Tanpa "=" kid dikosongkan.
+func splitKeyID(entry string) (string, string) {
+	if id, value, ok := strings.Cut(entry, "="); ok {
+		return id, value
+	}
+	return "", entry
+}
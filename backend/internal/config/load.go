@@ -0,0 +1,327 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository-un/internal/filetype"
+	"repository-un/internal/logging"
+	"repository-un/internal/scanner"
+	"repository-un/internal/utils"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// App adalah konfigurasi server yang aktif, diisi oleh Load
+var App = Default()
+
+// ValidationError berisi semua pengaturan yang tidak valid
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "Konfigurasi tidak valid:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load membaca konfigurasi dari default, file path (boleh kosong) dan
+// environment, memvalidasinya lalu menyimpannya di App. Semua pengaturan
+// yang tidak valid dilaporkan sekaligus dalam *ValidationError.
+func Load(path string) error {
+	c := Default()
+	var problems []string
+
+	if path != "" {
+		if err := readFile(path, &c); err != nil {
+			return &ValidationError{Problems: []string{err.Error()}}
+		}
+	}
+
+	problems = append(problems, applyEnv(reflect.ValueOf(&c).Elem(), os.LookupEnv)...)
+	c.OAI.BaseURL = strings.TrimSuffix(c.OAI.BaseURL, "/")
+	problems = append(problems, c.Validate()...)
+
+	keys, keyProblems := loadJWTKeys(c.JWT)
+	problems = append(problems, keyProblems...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	App = c
+	JWTKeys = keys
+	return nil
+}
+
+// readFile membaca file konfigurasi YAML (.yaml/.yml) atau TOML (.toml).
+// Key yang tidak dikenal dianggap error agar salah ketik tidak terlewat.
+func readFile(path string, c *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("gagal membaca file konfigurasi: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: key tidak dikenal: %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: format file konfigurasi harus .yaml, .yml atau .toml", path)
+	}
+	return nil
+}
+
+// applyEnv mengisi field bertag env dari environment. Mengembalikan
+// daftar nilai yang tidak bisa dibaca.
+func applyEnv(v reflect.Value, lookup func(string) (string, bool)) []string {
+	var problems []string
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field, fv := t.Field(i), v.Field(i)
+
+		name := field.Tag.Get("env")
+		if name == "" {
+			if fv.Kind() == reflect.Struct {
+				problems = append(problems, applyEnv(fv, lookup)...)
+			}
+			continue
+		}
+
+		raw, ok := lookup(name)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setField(fv, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return problems
+}
+
+// setField mengisi satu field dari nilai teks environment
+func setField(fv reflect.Value, raw string) error {
+	if u, ok := fv.Addr().Interface().(interface{ UnmarshalText([]byte) error }); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	if fv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("durasi tidak valid: %q", raw)
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("harus berupa angka: %q", raw)
+		}
+		fv.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("harus true atau false: %q", raw)
+		}
+		fv.SetBool(b)
+	case reflect.Slice:
+		fv.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("tipe %s tidak didukung", fv.Type())
+	}
+	return nil
+}
+
+// Validate memeriksa semua pengaturan dan mengembalikan daftar masalah
+func (c *Config) Validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.Env != EnvProduction && c.Env != EnvDevelopment {
+		add("env (APP_ENV) harus \"production\" atau \"development\": %q", c.Env)
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		add("server.addr (SERVER_ADDR) tidak valid: %q", c.Server.Addr)
+	}
+
+	for _, d := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server.read_header_timeout (SERVER_READ_HEADER_TIMEOUT)", c.Server.ReadHeaderTimeout},
+		{"server.read_timeout (SERVER_READ_TIMEOUT)", c.Server.ReadTimeout},
+		{"server.write_timeout (SERVER_WRITE_TIMEOUT)", c.Server.WriteTimeout},
+		{"server.idle_timeout (SERVER_IDLE_TIMEOUT)", c.Server.IdleTimeout},
+		{"server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT)", c.Server.ShutdownTimeout},
+	} {
+		if d.value <= 0 {
+			add("%s harus lebih dari 0", d.name)
+		}
+	}
+
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		add("server.tls.cert_file (TLS_CERT_FILE) dan server.tls.key_file (TLS_KEY_FILE) harus diisi bersamaan")
+	}
+
+	if c.Database.URL == "" {
+		add("database.url (DATABASE_URL) wajib diisi")
+	}
+
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			add("storage.local_dir (STORAGE_LOCAL_DIR) wajib diisi untuk driver local")
+		}
+	case "s3":
+		if c.Storage.S3.Endpoint == "" {
+			add("storage.s3.endpoint (S3_ENDPOINT) wajib diisi untuk driver s3")
+		}
+		if c.Storage.S3.Bucket == "" {
+			add("storage.s3.bucket (S3_BUCKET) wajib diisi untuk driver s3")
+		}
+	default:
+		add("storage.driver (STORAGE_DRIVER) harus \"local\" atau \"s3\": %q", c.Storage.Driver)
+	}
+
+	if c.Upload.MaxMemory <= 0 {
+		add("upload.max_memory (UPLOAD_MAX_MEMORY) harus lebih dari 0")
+	}
+	if c.Upload.ChunkSize <= 0 {
+		add("upload.chunk_size (UPLOAD_CHUNK_SIZE) harus lebih dari 0")
+	}
+	if c.Upload.MaxSize < c.Upload.ChunkSize {
+		add("upload.max_size (UPLOAD_MAX_SIZE) tidak boleh lebih kecil dari upload.chunk_size")
+	}
+	if c.Upload.SessionTTL < time.Minute {
+		add("upload.session_ttl (UPLOAD_SESSION_TTL) minimal 1m")
+	}
+	if len(c.Upload.AllowedTypes) == 0 {
+		add("upload.allowed_types (UPLOAD_ALLOWED_TYPES) wajib diisi")
+	}
+	for _, name := range c.Upload.AllowedTypes {
+		if !slices.Contains(filetype.Names, name) {
+			add("upload.allowed_types (UPLOAD_ALLOWED_TYPES) berisi format tidak dikenal: %q, pilihan: %s",
+				name, strings.Join(filetype.Names, ", "))
+		}
+	}
+	for _, name := range filetype.Names {
+		if c.Upload.TypeMaxSize.For(name) <= 0 {
+			add("upload.type_max_size.%s (UPLOAD_MAX_SIZE_%s) harus lebih dari 0", name, strings.ToUpper(name))
+		}
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		add("cors.allowed_origins (CORS_ALLOWED_ORIGINS) wajib diisi, gunakan \"*\" untuk semua origin")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin != "*" && !isOrigin(origin) {
+			add("cors.allowed_origins (CORS_ALLOWED_ORIGINS) berisi origin tidak valid: %q", origin)
+		}
+	}
+
+	if c.JWT.Secret != "" && len(c.JWT.Secret) < 32 {
+		add("jwt.secret (JWT_SECRET) minimal 32 karakter")
+	}
+	if c.JWT.PrivateKeyFile == "" && c.JWT.Secret == "" && c.Env != EnvDevelopment {
+		add("jwt.private_key_file (JWT_PRIVATE_KEY_FILE) atau jwt.secret (JWT_SECRET) wajib diisi, " +
+			"secret acak hanya boleh dipakai dengan APP_ENV=development")
+	}
+
+	if c.Metrics.Token != "" && len(c.Metrics.Token) < 32 {
+		add("metrics.token (METRICS_TOKEN) minimal 32 karakter")
+	}
+
+	if c.Preview.Pdftoppm == "" {
+		add("preview.pdftoppm (PDFTOPPM_PATH) wajib diisi")
+	}
+	if _, ok := utils.ImageFormats[c.Preview.ImageFormat]; !ok {
+		add("preview.image_format (PREVIEW_IMAGE_FORMAT) harus \"jpg\" atau \"png\": %q", c.Preview.ImageFormat)
+	}
+
+	if c.OAI.RepositoryName == "" {
+		add("oai.repository_name (OAI_REPOSITORY_NAME) wajib diisi")
+	}
+	if c.OAI.RepositoryIdentifier == "" || strings.ContainsAny(c.OAI.RepositoryIdentifier, ": /") {
+		add("oai.repository_id (OAI_REPOSITORY_ID) harus berupa nama domain: %q", c.OAI.RepositoryIdentifier)
+	}
+	if !strings.Contains(c.OAI.AdminEmail, "@") {
+		add("oai.admin_email (OAI_ADMIN_EMAIL) tidak valid: %q", c.OAI.AdminEmail)
+	}
+	if c.OAI.BaseURL != "" && !isHTTPURL(c.OAI.BaseURL) {
+		add("oai.base_url (OAI_BASE_URL) harus berupa URL http(s): %q", c.OAI.BaseURL)
+	}
+
+	if c.Jobs.Workers < 1 {
+		add("jobs.workers (JOB_WORKERS) minimal 1")
+	}
+
+	switch c.Scanner.Driver {
+	case "none":
+	case "clamd":
+		if _, _, err := scanner.ParseAddress(c.Scanner.ClamdAddress); err != nil {
+			add("scanner.clamd_address (CLAMD_ADDRESS) %v", err)
+		}
+	default:
+		add("scanner.driver (SCANNER_DRIVER) harus \"none\" atau \"clamd\": %q", c.Scanner.Driver)
+	}
+	if c.Scanner.Timeout <= 0 {
+		add("scanner.timeout (SCANNER_TIMEOUT) harus lebih dari 0")
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		add("log.level (LOG_LEVEL) harus \"debug\", \"info\", \"warn\" atau \"error\": %q", c.Log.Level)
+	}
+	if c.Log.Format != logging.FormatText && c.Log.Format != logging.FormatJSON {
+		add("log.format (LOG_FORMAT) harus \"text\" atau \"json\": %q", c.Log.Format)
+	}
+
+	return problems
+}
+
+// RenderOptions mengembalikan pengaturan render untuk utils.RenderPDFPages
+func (p PreviewConfig) RenderOptions() utils.RenderOptions {
+	return utils.RenderOptions{Pdftoppm: p.Pdftoppm, Format: p.ImageFormat}
+}
+
+// isHTTPURL mengecek apakah s adalah URL http atau https absolut
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isOrigin mengecek apakah s berbentuk origin "scheme://host[:port]"
+func isOrigin(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && isHTTPURL(s) && u.Path == "" && u.RawQuery == ""
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+
+	"repository-un/internal/logging"
+)
+
+// SetupLogger memasang logger slog global sesuai App.Log. Log dari
+// package log bawaan juga diteruskan ke logger ini.
+func SetupLogger() {
+	logger, err := logging.New(os.Stderr, App.Log.Level, App.Log.Format)
+	if err != nil {
+		// App.Log sudah divalidasi oleh Load
+		fatal("Pengaturan log tidak valid", err)
+	}
+	slog.SetDefault(logger)
+}
+
+// fatal mencatat error lalu menghentikan server
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"log/slog"
+
+	"repository-un/internal/scanner"
+)
+
+// Scanner adalah pemindai malware file upload global. Default-nya tidak
+// memindai (semua file dianggap bersih).
+var Scanner scanner.Scanner = scanner.Nop{}
+
+// SetupScanner menyiapkan pemindai sesuai App.Scanner.Driver ("none" atau "clamd")
+func SetupScanner() {
+	c := App.Scanner
+	if c.Driver != "clamd" {
+		slog.Warn("Pemindaian malware file upload tidak aktif", "driver", c.Driver)
+		return
+	}
+
+	clamd, err := scanner.NewClamd(c.ClamdAddress, c.Timeout)
+	if err != nil {
+		fatal("Gagal menyiapkan scanner clamd", err)
+	}
+	Scanner = clamd
+
+	slog.Info("✅ Scanner ready", "driver", c.Driver, "address", c.ClamdAddress)
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"repository-un/internal/storage"
+)
+
+// Storage adalah tempat penyimpanan file dokumen global
+var Storage storage.Storage
+
+// ConnectStorage menyiapkan storage sesuai App.Storage.Driver ("local" atau "s3")
+func ConnectStorage() {
+	c := App.Storage
+
+	switch c.Driver {
+	case "local":
+		local, err := storage.NewLocal(c.LocalDir)
+		if err != nil {
+			fatal("Gagal menyiapkan storage lokal", err)
+		}
+		Storage = local
+
+	case "s3":
+		s3, err := storage.NewS3(context.Background(), storage.S3Config{
+			Endpoint:        c.S3.Endpoint,
+			AccessKeyID:     c.S3.AccessKeyID,
+			SecretAccessKey: c.S3.SecretAccessKey,
+			Bucket:          c.S3.Bucket,
+			Region:          c.S3.Region,
+			UseSSL:          c.S3.UseSSL,
+		})
+		if err != nil {
+			fatal("Gagal koneksi ke storage S3", err)
+		}
+		Storage = s3
+
+	default:
+		fatal("Gagal menyiapkan storage", errors.New("STORAGE_DRIVER tidak dikenal: "+c.Driver))
+	}
+
+	slog.Info("✅ Storage ready", "driver", c.Driver)
+}
@@ -0,0 +1,134 @@
+// Package filetype mendeteksi format file dari isinya (magic bytes), bukan
+// dari ekstensi nama file yang dikirim client.
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Type adalah format file hasil deteksi
+type Type struct {
+	// Name adalah kelompok format yang dipakai di allow-list upload,
+	// semua format gambar memakai "image"
+	Name string
+	MIME string
+	Ext  string
+}
+
+// Kelompok format yang bisa diizinkan untuk upload
+const (
+	NamePDF   = "pdf"
+	NameDOCX  = "docx"
+	NameODT   = "odt"
+	NameEPUB  = "epub"
+	NameImage = "image"
+	NameZIP   = "zip"
+)
+
+// Names berisi semua kelompok format yang dikenali
+var Names = []string{NamePDF, NameDOCX, NameODT, NameEPUB, NameImage, NameZIP}
+
+// Format yang dikenali
+var (
+	PDF  = Type{NamePDF, "application/pdf", ".pdf"}
+	DOCX = Type{NameDOCX, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", ".docx"}
+	ODT  = Type{NameODT, "application/vnd.oasis.opendocument.text", ".odt"}
+	EPUB = Type{NameEPUB, "application/epub+zip", ".epub"}
+	PNG  = Type{NameImage, "image/png", ".png"}
+	JPEG = Type{NameImage, "image/jpeg", ".jpg"}
+	GIF  = Type{NameImage, "image/gif", ".gif"}
+	WebP = Type{NameImage, "image/webp", ".webp"}
+	TIFF = Type{NameImage, "image/tiff", ".tiff"}
+	ZIP  = Type{NameZIP, "application/zip", ".zip"}
+)
+
+// ErrUnknown dikembalikan jika isi file tidak cocok dengan format apa pun
+var ErrUnknown = errors.New("filetype: format file tidak dikenali")
+
+// sniffLen adalah jumlah byte awal yang dibaca untuk mencari magic bytes
+const sniffLen = 64
+
+// pdfJunkLen adalah jumlah byte sampah (misalnya BOM atau baris kosong dari
+// generator lama) yang boleh mendahului header "%PDF-". Jendelanya sengaja
+// kecil agar file teks yang sekadar memuat "%PDF-" tidak dianggap PDF.
+const pdfJunkLen = 8
+
+// magic adalah tanda di awal file untuk format selain ZIP
+var magic = []struct {
+	prefix []byte
+	typ    Type
+}{
+	{[]byte("\x89PNG\r\n\x1a\n"), PNG},
+	{[]byte("\xff\xd8\xff"), JPEG},
+	{[]byte("GIF87a"), GIF},
+	{[]byte("GIF89a"), GIF},
+	{[]byte("II*\x00"), TIFF},
+	{[]byte("MM\x00*"), TIFF},
+}
+
+// Detect mengenali format file r berukuran size. Dokumen berbasis ZIP
+// (DOCX, ODT, EPUB) dibedakan dari isi arsipnya.
+func Detect(r io.ReaderAt, size int64) (Type, error) {
+	head := make([]byte, min(size, sniffLen))
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return Type{}, err
+	}
+	head = head[:n]
+
+	switch {
+	case bytes.Contains(head[:min(len(head), pdfJunkLen+len("%PDF-"))], []byte("%PDF-")):
+		return PDF, nil
+	case len(head) >= 12 && bytes.HasPrefix(head, []byte("RIFF")) && string(head[8:12]) == "WEBP":
+		return WebP, nil
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return detectZip(r, size)
+	}
+	for _, m := range magic {
+		if bytes.HasPrefix(head, m.prefix) {
+			return m.typ, nil
+		}
+	}
+	return Type{}, ErrUnknown
+}
+
+// detectZip membedakan ODT/EPUB (file "mimetype" di awal arsip), DOCX
+// (berisi word/document.xml) dan arsip ZIP biasa. Arsip yang rusak
+// dianggap tidak dikenali.
+func detectZip(r io.ReaderAt, size int64) (Type, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return Type{}, ErrUnknown
+	}
+
+	for _, f := range zr.File {
+		switch f.Name {
+		case "mimetype":
+			switch readMimetype(f) {
+			case ODT.MIME:
+				return ODT, nil
+			case EPUB.MIME:
+				return EPUB, nil
+			}
+		case "word/document.xml":
+			return DOCX, nil
+		}
+	}
+	return ZIP, nil
+}
+
+// readMimetype membaca isi file "mimetype" dalam arsip ODF/EPUB
+func readMimetype(f *zip.File) string {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(rc, 128))
+	return strings.TrimSpace(string(data))
+}
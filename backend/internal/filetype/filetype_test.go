@@ -0,0 +1,64 @@
+package filetype
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// zipFile membuat arsip ZIP berisi files (nama → isi) sesuai urutan names
+func zipFile(t *testing.T, names []string, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(files[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    Type
+		wantErr error
+	}{
+		{"pdf", []byte("%PDF-1.7\n%âãÏÓ\n1 0 obj"), PDF, nil},
+		{"pdf with bom", []byte("\xef\xbb\xbf%PDF-1.4\n"), PDF, nil},
+		{"text containing pdf header", []byte("Contoh header file PDF adalah %PDF-1.7, bukan file PDF"), Type{}, ErrUnknown},
+		{"pdf header after junk window", append(bytes.Repeat([]byte(" "), pdfJunkLen+1), "%PDF-1.7"...), Type{}, ErrUnknown},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), PNG, nil},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), JPEG, nil},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), WebP, nil},
+		{"docx", zipFile(t, []string{"[Content_Types].xml", "word/document.xml"},
+			map[string]string{"word/document.xml": "<w:document/>"}), DOCX, nil},
+		{"odt", zipFile(t, []string{"mimetype", "content.xml"},
+			map[string]string{"mimetype": ODT.MIME}), ODT, nil},
+		{"epub", zipFile(t, []string{"mimetype", "META-INF/container.xml"},
+			map[string]string{"mimetype": EPUB.MIME}), EPUB, nil},
+		{"zip", zipFile(t, []string{"data.csv"}, map[string]string{"data.csv": "a,b\n1,2\n"}), ZIP, nil},
+		{"broken zip", []byte("PK\x03\x04rusak"), Type{}, ErrUnknown},
+		{"text", []byte("hanya teks biasa"), Type{}, ErrUnknown},
+		{"empty", nil, Type{}, ErrUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(bytes.NewReader(tt.data), int64(len(tt.data)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Detect error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
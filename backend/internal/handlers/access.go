@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/workflow"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// viewer adalah identitas pemanggil yang di-set oleh AuthMiddleware /
+// OptionalAuthMiddleware. UserID kosong berarti anonim.
+type viewer struct {
+	UserID string
+	Role   string
+}
+
+// currentViewer membaca identitas pemanggil dari header request
+func currentViewer(r *http.Request) viewer {
+	return viewer{
+		UserID: r.Header.Get("X-User-ID"),
+		Role:   r.Header.Get("X-User-Role"),
+	}
+}
+
+func (v viewer) isAnonymous() bool {
+	return v.UserID == ""
+}
+
+func (v viewer) isAdmin() bool {
+	return v.UserID != "" && v.Role == "admin"
+}
+
+// canView menentukan apakah dokumen boleh dilihat:
+// admin melihat semua, pemilik melihat dokumennya sendiri,
+// selain itu hanya dokumen published.
+func (v viewer) canView(doc documentAccess) bool {
+	return doc.Status == workflow.StatusPublished || v.canEdit(doc)
+}
+
+// canEdit menentukan apakah dokumen boleh diubah/dihapus (pemilik atau admin)
+func (v viewer) canEdit(doc documentAccess) bool {
+	if v.isAdmin() {
+		return true
+	}
+	return !v.isAnonymous() && doc.OwnerID != nil && *doc.OwnerID == v.UserID
+}
+
+// visibleCondition mengembalikan kondisi SQL yang membatasi dokumen sesuai
+// canView. column adalah prefix tabel documents (misalnya "d." atau "").
+// argIndex adalah nomor placeholder berikutnya yang bebas dipakai.
+func (v viewer) visibleCondition(column string, argIndex int) (string, []interface{}) {
+	switch {
+	case v.isAdmin():
+		return "TRUE", nil
+	case v.isAnonymous():
+		return fmt.Sprintf("%sstatus = '%s'", column, workflow.StatusPublished), nil
+	default:
+		return fmt.Sprintf("(%sstatus = '%s' OR %sowner_id = $%d)",
+			column, workflow.StatusPublished, column, argIndex), []interface{}{v.UserID}
+	}
+}
+
+// documentAccess berisi data dokumen yang dibutuhkan untuk cek akses
+type documentAccess struct {
+	ID      string
+	OwnerID *string
+	Status  string
+}
+
+// loadDocumentAccess mengambil pemilik dan status dokumen
+func loadDocumentAccess(ctx context.Context, id string) (documentAccess, error) {
+	doc := documentAccess{ID: id}
+	err := config.DB.QueryRow(ctx,
+		`SELECT owner_id, status FROM documents WHERE id = $1`, id).Scan(&doc.OwnerID, &doc.Status)
+	return doc, err
+}
+
+// authorizeView memastikan dokumen ada dan boleh dilihat pemanggil.
+// Dokumen yang tidak boleh dilihat dilaporkan sebagai tidak ditemukan
+// agar keberadaannya tidak bocor. Mengembalikan false jika response
+// error sudah ditulis.
+func authorizeView(w http.ResponseWriter, r *http.Request, id string) (documentAccess, bool) {
+	doc, err := loadDocumentAccess(r.Context(), id)
+	if err != nil || !currentViewer(r).canView(doc) {
+		apierror.Write(w, r, errDocumentNotFound)
+		return doc, false
+	}
+	return doc, true
+}
+
+// authorizeEdit memastikan pemanggil adalah pemilik dokumen atau admin.
+// Mengembalikan false jika response error sudah ditulis.
+func authorizeEdit(w http.ResponseWriter, r *http.Request, id string) (documentAccess, bool) {
+	v := currentViewer(r)
+	if v.isAnonymous() {
+		apierror.Write(w, r, apierror.Unauthorized)
+		return documentAccess{}, false
+	}
+
+	doc, ok := authorizeView(w, r, id)
+	if !ok {
+		return doc, false
+	}
+
+	if !v.canEdit(doc) {
+		apierror.Write(w, r, errDocumentForbidden)
+		return doc, false
+	}
+	return doc, true
+}
+
+// authorizeContentEdit adalah authorizeEdit untuk perubahan file, metadata
+// atau versi aktif, yang juga menolak dokumen yang terkunci oleh review.
+// Status dicek ulang di dalam transaksi dengan lockEditableDocument.
+func authorizeContentEdit(w http.ResponseWriter, r *http.Request, id string) (documentAccess, bool) {
+	doc, ok := authorizeEdit(w, r, id)
+	if !ok {
+		return doc, false
+	}
+	if err := checkEditable(doc.Status); err != nil {
+		apierror.Write(w, r, err)
+		return doc, false
+	}
+	return doc, true
+}
+
+// lockEditableDocument mengunci baris dokumen id di dalam tx dan memastikan
+// statusnya masih boleh diubah, sehingga transisi ke in_review atau
+// published tidak bisa menyusul di tengah perubahan
+func lockEditableDocument(ctx context.Context, tx dbExecutor, id string) error {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM documents WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errDocumentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return checkEditable(status)
+}
+
+// checkEditable menolak perubahan isi dokumen berstatus status, lihat
+// workflow.Editable
+func checkEditable(status string) error {
+	if !workflow.Editable(status) {
+		return errDocumentLocked.WithMessage(apierror.Textf(
+			"Dokumen berstatus %s tidak dapat diubah", "Documents with status %s cannot be modified", status))
+	}
+	return nil
+}
+
+// requireLogin memastikan request berasal dari user yang login.
+// Mengembalikan false jika response error sudah ditulis.
+func requireLogin(w http.ResponseWriter, r *http.Request) bool {
+	if currentViewer(r).isAnonymous() {
+		apierror.Write(w, r, apierror.Unauthorized)
+		return false
+	}
+	return true
+}
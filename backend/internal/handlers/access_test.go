@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/workflow"
+)
+
+func TestCheckEditable(t *testing.T) {
+	tests := []struct {
+		status string
+		locked bool
+	}{
+		{workflow.StatusDraft, false},
+		{workflow.StatusSubmitted, false},
+		{workflow.StatusRejected, false},
+		{workflow.StatusArchived, false},
+		{workflow.StatusInReview, true},
+		{workflow.StatusPublished, true},
+	}
+	for _, tt := range tests {
+		err := checkEditable(tt.status)
+		if !tt.locked {
+			if err != nil {
+				t.Errorf("checkEditable(%s) = %v, want nil", tt.status, err)
+			}
+			continue
+		}
+
+		var apiErr *apierror.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != errDocumentLocked.Code {
+			t.Fatalf("checkEditable(%s) = %v, want %s", tt.status, err, errDocumentLocked.Code)
+		}
+		w := httptest.NewRecorder()
+		apierror.Write(w, httptest.NewRequest(http.MethodPut, "/api/documents/x", nil), err)
+		if w.Code != http.StatusConflict {
+			t.Errorf("status = %d, want 409", w.Code)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+	"repository-un/internal/requestid"
+	"repository-un/internal/router"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Aksi yang dicatat di audit_events
+const (
+	auditLogin          = "auth.login"
+	auditLoginFailed    = "auth.login_failed"
+	auditRegister       = "auth.register"
+	auditLogout         = "auth.logout"
+	auditDocumentCreate = "document.create"
+	auditDocumentUpdate = "document.update"
+	auditDocumentDelete = "document.delete"
+	auditDownload       = "document.download"
+	auditReprocess      = "document.reprocess"
+	auditRestore        = "document.restore_version"
+	auditTransition     = "document.transition"
+	auditUserCreate     = "user.create"
+	auditUserUpdate     = "user.update"
+	auditUserDelete     = "user.delete"
+)
+
+// Jenis target audit
+const (
+	targetDocument = "document"
+	targetUser     = "user"
+)
+
+// auditEvent adalah satu kejadian yang akan dicatat. Actor default-nya
+// user yang sedang login; ActorID/ActorEmail diisi sendiri pada route
+// tanpa middleware autentikasi (login, registrasi, logout).
+type auditEvent struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	ActorID    string
+	ActorEmail string
+	Details    map[string]interface{}
+}
+
+// recordAudit menyimpan kejadian ke audit_events. Kegagalan mencatat
+// hanya ditulis ke log agar aksi user yang sudah berhasil tidak ikut gagal.
+func recordAudit(r *http.Request, e auditEvent) {
+	if e.ActorID == "" && e.ActorEmail == "" {
+		e.ActorID = currentViewer(r).UserID
+		e.ActorEmail = r.Header.Get("X-User-Email")
+	}
+	var actorID *string
+	if e.ActorID != "" {
+		actorID = &e.ActorID
+	}
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+
+	// Tetap dicatat walaupun client sudah memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
+	_, err := config.DB.Exec(ctx,
+		`INSERT INTO audit_events (id, actor_id, actor_email, action, target_type, target_id,
+		                           details, ip_address, user_agent, request_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		uuid.New().String(), actorID, truncate(e.ActorEmail, 255), e.Action, e.TargetType, e.TargetID,
+		e.Details, clientIP(r), truncate(r.UserAgent(), 512), requestid.FromContext(ctx))
+	if err != nil {
+		slog.ErrorContext(ctx, "Gagal mencatat audit", "action", e.Action, "target_id", e.TargetID, "error", err)
+	}
+}
+
+// isNewDownload membedakan download baru dari HEAD dan lanjutan download
+// (header Range selain dari byte 0) agar satu download tidak tercatat
+// berkali-kali
+func isNewDownload(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	rangeHeader := r.Header.Get("Range")
+	return rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")
+}
+
+// auditParams berisi filter list audit yang sudah divalidasi
+type auditParams struct {
+	Page       int
+	Limit      int
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// parseAuditParams membaca dan memvalidasi query string list audit
+func parseAuditParams(r *http.Request) (auditParams, error) {
+	q := r.URL.Query()
+	p := auditParams{
+		Actor:      strings.TrimSpace(q.Get("actor")),
+		Action:     strings.TrimSpace(q.Get("action")),
+		TargetType: strings.TrimSpace(q.Get("target_type")),
+		TargetID:   strings.TrimSpace(q.Get("target_id")),
+	}
+
+	var err error
+	p.Page, p.Limit, err = parsePagination(q)
+	if err != nil {
+		return p, err
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := parseDateParam(v, false)
+		if err != nil {
+			return p, errInvalidQuery.WithFields(apierror.Invalid("from"))
+		}
+		p.From = &from
+	}
+
+	if v := q.Get("to"); v != "" {
+		to, err := parseDateParam(v, true)
+		if err != nil {
+			return p, errInvalidQuery.WithFields(apierror.Invalid("to"))
+		}
+		p.To = &to
+	}
+
+	return p, nil
+}
+
+// where menyusun klausa WHERE beserta argumennya. actor berupa id user
+// atau email, action yang diakhiri "*" dicocokkan sebagai awalan
+// (misalnya "document.*").
+func (p auditParams) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if p.Actor != "" {
+		if router.UUID(p.Actor) {
+			add("actor_id = $%d", p.Actor)
+		} else {
+			add("lower(actor_email) = lower($%d)", p.Actor)
+		}
+	}
+	if prefix, ok := strings.CutSuffix(p.Action, "*"); ok {
+		add("action LIKE $%d", escapeLike(prefix)+"%")
+	} else if p.Action != "" {
+		add("action = $%d", p.Action)
+	}
+	if p.TargetType != "" {
+		add("target_type = $%d", p.TargetType)
+	}
+	if p.TargetID != "" {
+		add("target_id = $%d", p.TargetID)
+	}
+	if p.From != nil {
+		add("created_at >= $%d", *p.From)
+	}
+	if p.To != nil {
+		add("created_at < $%d", *p.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// auditColumns adalah kolom yang dibaca oleh scanAuditEvent
+const auditColumns = `id, actor_id, actor_email, action, target_type, target_id,
+	details, ip_address, user_agent, request_id, created_at`
+
+func scanAuditEvent(row pgx.Row) (models.AuditEvent, error) {
+	var e models.AuditEvent
+	err := row.Scan(&e.ID, &e.ActorID, &e.ActorEmail, &e.Action, &e.TargetType, &e.TargetID,
+		&e.Details, &e.IPAddress, &e.UserAgent, &e.RequestID, &e.CreatedAt)
+	return e, err
+}
+
+// listAuditEvents mengembalikan catatan audit terbaru lebih dulu (admin)
+// GET /api/audit
+// Query params:
+//   - page, limit             : halaman (mulai 1) dan jumlah per halaman (maks 100)
+//   - actor                   : id atau email user yang melakukan aksi
+//   - action                  : aksi persis, atau awalan seperti "document.*"
+//   - target_type, target_id  : target aksi, misalnya document dan id dokumen
+//   - from, to                : rentang tanggal (YYYY-MM-DD atau RFC3339)
+//   - format=csv              : unduh semua hasil filter sebagai CSV tanpa pagination
+func listAuditEvents(w http.ResponseWriter, r *http.Request) {
+	params, err := parseAuditParams(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	where, args := params.where()
+
+	if r.URL.Query().Get("format") == "csv" {
+		exportAuditCSV(w, r, where, args)
+		return
+	}
+
+	var total int
+	err = config.DB.QueryRow(r.Context(),
+		`SELECT COUNT(*) FROM audit_events`+where, args...).Scan(&total)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+	rows, err := config.DB.Query(r.Context(),
+		fmt.Sprintf(`SELECT %s FROM audit_events%s
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $%d OFFSET $%d`, auditColumns, where, len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			apierror.Write(w, r, errReadFailed)
+			return
+		}
+		events = append(events, e)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.AuditEventList{
+		Data:       events,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (total + params.Limit - 1) / params.Limit,
+	})
+}
+
+// exportAuditCSV menulis semua catatan audit yang cocok sebagai CSV.
+// Baris ditulis langsung dari cursor database sehingga export besar tidak
+// dimuat ke memori.
+func exportAuditCSV(w http.ResponseWriter, r *http.Request, where string, args []interface{}) {
+	rows, err := config.DB.Query(r.Context(),
+		`SELECT `+auditColumns+` FROM audit_events`+where+` ORDER BY created_at DESC, id DESC`,
+		args...)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="audit-%s.csv"`, time.Now().Format("20060102-150405")))
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"created_at", "actor_id", "actor_email", "action", "target_type", "target_id",
+		"details", "ip_address", "user_agent", "request_id", "id"})
+
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			// Header sudah terkirim, export berhenti di sini
+			slog.ErrorContext(r.Context(), "Gagal membaca audit untuk CSV", "error", err)
+			break
+		}
+
+		actorID := ""
+		if e.ActorID != nil {
+			actorID = *e.ActorID
+		}
+		out.Write([]string{
+			e.CreatedAt.UTC().Format(time.RFC3339), actorID, csvSafe(e.ActorEmail), e.Action,
+			e.TargetType, e.TargetID, csvSafe(string(e.Details)), e.IPAddress, csvSafe(e.UserAgent),
+			e.RequestID, e.ID,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		slog.ErrorContext(r.Context(), "Gagal membaca audit untuk CSV", "error", err)
+	}
+	out.Flush()
+}
+
+// csvSafe mencegah nilai yang dikirim user (email, user agent) dibaca
+// sebagai rumus saat CSV dibuka di spreadsheet
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
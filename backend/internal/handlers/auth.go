@@ -1,11 +1,12 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
+	"repository-un/internal/apierror"
 	"repository-un/internal/config"
 	"repository-un/internal/middleware"
 	"repository-un/internal/models"
@@ -17,65 +18,57 @@ import (
 // LoginHandler menangani proses login user
 // POST /api/auth/login
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
-		return
-	}
-	middleware.EnableCORS(w)
-
-	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest)
 		return
 	}
 
-	if req.Email == "" || req.Password == "" {
-		http.Error(w, `{"error":"Email and password are required"}`, http.StatusBadRequest)
+	if fields := missingFields("email", req.Email, "password", req.Password); fields != nil {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(fields...))
 		return
 	}
 
 	// Ambil user dari database
 	var user models.User
-	err := config.DB.QueryRow(context.Background(),
-		`SELECT id, name, email, password, role, created_at, updated_at 
+	var tokenVersion int
+	err := config.DB.QueryRow(r.Context(),
+		`SELECT id, name, email, password, role, created_at, updated_at, token_version
 		 FROM users WHERE email = $1`, req.Email).Scan(
-		&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Name, &user.Email, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt, &tokenVersion,
 	)
 
 	if err != nil {
-		http.Error(w, `{"error":"Invalid email or password"}`, http.StatusUnauthorized)
+		recordAudit(r, auditEvent{Action: auditLoginFailed, TargetType: targetUser, ActorEmail: req.Email,
+			Details: map[string]interface{}{"reason": "unknown_email"}})
+		apierror.Write(w, r, errInvalidCredentials)
 		return
 	}
 
 	// Verifikasi password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		http.Error(w, `{"error":"Invalid email or password"}`, http.StatusUnauthorized)
+		recordAudit(r, auditEvent{Action: auditLoginFailed, TargetType: targetUser, TargetID: user.ID,
+			ActorEmail: user.Email, Details: map[string]interface{}{"reason": "wrong_password"}})
+		apierror.Write(w, r, errInvalidCredentials)
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(user.ID, user.Email, user.Role)
+	// Buat access token dan refresh token (sesi baru)
+	response, _, err := issueSession(r.Context(), config.DB, r, models.UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}, tokenVersion, "")
 	if err != nil {
-		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errTokenFailed)
 		return
 	}
 
-	response := models.AuthResponse{
-		Token: token,
-		User: models.UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			Role:      user.Role,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		},
-	}
+	recordAudit(r, auditEvent{Action: auditLogin, TargetType: targetUser, TargetID: user.ID,
+		ActorID: user.ID, ActorEmail: user.Email})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -84,47 +77,35 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 // RegisterHandler menangani proses registrasi user baru
 // POST /api/auth/register
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
-		return
-	}
-	middleware.EnableCORS(w)
-
-	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
-		return
-	}
-
 	var req models.RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest)
 		return
 	}
 
-	if req.Name == "" || req.Email == "" || req.Password == "" {
-		http.Error(w, `{"error":"Name, email, and password are required"}`, http.StatusBadRequest)
-		return
+	fields := missingFields("name", req.Name, "email", req.Email, "password", req.Password)
+	if req.Password != "" && len(req.Password) < minPasswordLength {
+		fields = append(fields, passwordTooShort())
 	}
-
-	if len(req.Password) < 6 {
-		http.Error(w, `{"error":"Password must be at least 6 characters"}`, http.StatusBadRequest)
+	if fields != nil {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(fields...))
 		return
 	}
 
 	// Cek apakah email sudah terdaftar
 	var exists bool
-	config.DB.QueryRow(context.Background(),
+	config.DB.QueryRow(r.Context(),
 		`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, req.Email).Scan(&exists)
 
 	if exists {
-		http.Error(w, `{"error":"Email already registered"}`, http.StatusConflict)
+		apierror.Write(w, r, errEmailTaken)
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, `{"error":"Failed to process password"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errPasswordFailed)
 		return
 	}
 
@@ -132,74 +113,161 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 
 	// Insert user dengan role default "user"
-	_, err = config.DB.Exec(context.Background(),
+	_, err = config.DB.Exec(r.Context(),
 		`INSERT INTO users (id, name, email, password, role, created_at, updated_at)
 		 VALUES ($1, $2, $3, $4, 'user', $5, $6)`,
 		id, req.Name, req.Email, string(hashedPassword), now, now,
 	)
 
 	if err != nil {
-		http.Error(w, `{"error":"Failed to create user"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errCreateUserFailed)
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(id, req.Email, "user")
+	// Buat access token dan refresh token (sesi baru)
+	response, _, err := issueSession(r.Context(), config.DB, r, models.UserResponse{
+		ID:        id,
+		Name:      req.Name,
+		Email:     req.Email,
+		Role:      "user",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, 0, "")
 	if err != nil {
-		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errTokenFailed)
 		return
 	}
 
-	response := models.AuthResponse{
-		Token: token,
-		User: models.UserResponse{
-			ID:        id,
-			Name:      req.Name,
-			Email:     req.Email,
-			Role:      "user",
-			CreatedAt: now,
-			UpdatedAt: now,
-		},
-	}
+	recordAudit(r, auditEvent{Action: auditRegister, TargetType: targetUser, TargetID: id,
+		ActorID: id, ActorEmail: req.Email})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetMeHandler mengembalikan data user yang sedang login
-// GET /api/auth/me
-func GetMeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
+// RefreshHandler menukar refresh token dengan access token dan refresh
+// token baru. Refresh token lama langsung tidak berlaku.
+// POST /api/auth/refresh
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.BadRequest)
 		return
 	}
-	middleware.EnableCORS(w)
 
-	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+	if req.RefreshToken == "" {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(apierror.Required("refresh_token")))
 		return
 	}
 
+	response, err := rotateRefreshToken(r.Context(), r, req.RefreshToken)
+	if errors.Is(err, errInvalidRefreshToken) {
+		apierror.Write(w, r, errRefreshTokenDenied)
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, errRefreshFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// LogoutHandler mengakhiri sesi: access token yang dikirim dicabut dan
+// refresh token beserta family-nya tidak berlaku lagi. Dengan "all": true
+// semua sesi user di semua perangkat diakhiri.
+// Access token boleh sudah kedaluwarsa asalkan refresh token dikirim.
+// POST /api/auth/logout
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.LogoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, r, apierror.BadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	var userID string
+
+	if token := middleware.GetTokenFromHeader(r); token != "" {
+		if claims, err := middleware.ValidateToken(ctx, token); err == nil {
+			if err := middleware.RevokeToken(ctx, claims); err != nil {
+				apierror.Write(w, r, errLogoutFailed)
+				return
+			}
+			userID = claims.UserID
+		}
+	}
+
+	if req.RefreshToken != "" {
+		var familyID, ownerID string
+		err := config.DB.QueryRow(ctx,
+			`SELECT family_id, user_id FROM refresh_tokens WHERE token_hash = $1`,
+			hashRefreshToken(req.RefreshToken)).Scan(&familyID, &ownerID)
+		if err == nil && (userID == "" || userID == ownerID) {
+			if err := revokeRefreshFamily(ctx, config.DB, familyID); err != nil {
+				apierror.Write(w, r, errLogoutFailed)
+				return
+			}
+			userID = ownerID
+		}
+	}
+
+	if userID == "" {
+		apierror.Write(w, r, apierror.InvalidToken)
+		return
+	}
+
+	if req.All {
+		if err := revokeUserSessions(ctx, config.DB, userID); err != nil {
+			apierror.Write(w, r, errLogoutFailed)
+			return
+		}
+	}
+
+	cleanupExpiredTokens(ctx)
+
+	recordAudit(r, auditEvent{Action: auditLogout, TargetType: targetUser, TargetID: userID,
+		ActorID: userID, Details: map[string]interface{}{"all_sessions": req.All}})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message":"Logged out successfully"}`))
+}
+
+// GetMeHandler mengembalikan data user yang sedang login
+// GET /api/auth/me
+func GetMeHandler(w http.ResponseWriter, r *http.Request) {
 	// Ambil user ID dari header (di-set oleh AuthMiddleware)
 	userID := r.Header.Get("X-User-ID")
 	if userID == "" {
-		http.Error(w, `{"error":"User not found"}`, http.StatusUnauthorized)
+		apierror.Write(w, r, apierror.Unauthorized)
 		return
 	}
 
 	var user models.UserResponse
-	err := config.DB.QueryRow(context.Background(),
+	err := config.DB.QueryRow(r.Context(),
 		`SELECT id, name, email, role, created_at, updated_at 
 		 FROM users WHERE id = $1`, userID).Scan(
 		&user.ID, &user.Name, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err != nil {
-		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+		apierror.Write(w, r, errUserNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
+
+// JWKSHandler mempublikasikan public key untuk verifikasi access token
+// oleh service lain. Kosong jika token ditandatangani dengan HS256.
+// GET /.well-known/jwks.json
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(config.JWTKeys.JWKS())
+}
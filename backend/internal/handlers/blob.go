@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/filetype"
+	"repository-un/internal/models"
+	"repository-un/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// stagingDir adalah lokasi file upload sebelum dicatat sebagai blob.
+// Nama blob baru diketahui setelah seluruh isi file selesai di-hash.
+const stagingDir = "staging"
+
+// blobKey mengembalikan lokasi blob dengan hash sum. File dengan isi yang
+// sama selalu mendapat key yang sama.
+func blobKey(sum, ext string) string {
+	return storage.Join("blobs", sum[:2], sum+ext)
+}
+
+// stageFile menyimpan isi r ke area staging sambil menghitung SHA-256.
+// Path file hasil adalah lokasi blob yang diisi saat versi dicatat,
+// lihat registerBlob.
+func stageFile(ctx context.Context, r io.Reader, name string, size int64, t filetype.Type) (storedFile, error) {
+	f := storedFile{
+		Name:     name,
+		Size:     size,
+		MimeType: t.MIME,
+		Staging:  storage.Join(stagingDir, uuid.New().String()+t.Ext),
+	}
+
+	hash := sha256.New()
+	if err := config.Storage.Put(ctx, f.Staging, io.TeeReader(r, hash), size, t.MIME); err != nil {
+		config.Storage.Remove(ctx, f.Staging)
+		return f, err
+	}
+
+	f.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	f.Path = blobKey(f.SHA256, t.Ext)
+	return f, nil
+}
+
+// registerBlob menambah referensi blob v.SHA256 di dalam tx lalu mengisi
+// v.FilePath dengan lokasi blob. File staging dipindah ke lokasi blob jika
+// blob baru atau filenya hilang; blob baru ikut dihapus jika tx batal.
+// Baris blob tetap terkunci sampai tx selesai sehingga tidak bentrok
+// dengan releaseBlob untuk hash yang sama.
+func registerBlob(ctx context.Context, tx *fileTx, v *models.DocumentVersion, staging string) error {
+	var refs int
+	err := tx.QueryRow(ctx,
+		`INSERT INTO blobs (sha256, file_path, size, mime_type, ref_count)
+		 VALUES ($1, $2, $3, $4, 1)
+		 ON CONFLICT (sha256) DO UPDATE SET ref_count = blobs.ref_count + 1
+		 RETURNING ref_count, file_path`,
+		v.SHA256, v.FilePath, v.FileSize, v.MimeType).Scan(&refs, &v.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// File staging yang tidak dipakai dihapus oleh tx
+	if refs > 1 {
+		if _, err := config.Storage.Stat(ctx, v.FilePath); err == nil {
+			return nil
+		}
+	}
+	if err := config.Storage.Move(ctx, staging, v.FilePath); err != nil {
+		return err
+	}
+	if refs == 1 {
+		tx.Created(v.FilePath)
+	}
+	return nil
+}
+
+// releaseBlobs menghapus blob sums yang sudah tidak dipakai versi mana pun
+func releaseBlobs(ctx context.Context, sums []string) {
+	for _, sum := range sums {
+		if err := releaseBlob(ctx, sum); err != nil {
+			slog.WarnContext(ctx, "Gagal menghapus blob", "sha256", sum, "error", err)
+		}
+	}
+}
+
+// releaseBlob menghapus file dan baris blob sum jika ref_count sudah 0.
+// Upload file yang sama di saat bersamaan menunggu kunci baris blob lalu
+// membuat blob baru, lihat registerBlob.
+func releaseBlob(ctx context.Context, sum string) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var filePath string
+	err = tx.QueryRow(ctx,
+		`SELECT file_path FROM blobs WHERE sha256 = $1 AND ref_count = 0 FOR UPDATE`, sum).Scan(&filePath)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Masih dipakai oleh versi lain
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := config.Storage.Remove(ctx, filePath); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM blobs WHERE sha256 = $1`, sum); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// checkDuplicate menolak file dengan hash sum jika isi yang sama sudah ada
+// di dokumen yang boleh dilihat pemanggil. Error berisi ID dokumen dan
+// nomor versi tersebut. Client bisa tetap mengupload dengan
+// allow_duplicate=true (query atau field form); file tetap disimpan sekali.
+func checkDuplicate(ctx context.Context, r *http.Request, sum string) error {
+	if allow, _ := strconv.ParseBool(r.FormValue("allow_duplicate")); allow {
+		return nil
+	}
+
+	cond, args := currentViewer(r).visibleCondition("d.", 2)
+	var id string
+	var number int
+	err := config.DB.QueryRow(ctx,
+		`SELECT d.id, v.version_number
+		 FROM document_versions v JOIN documents d ON d.id = v.document_id
+		 WHERE v.sha256 = $1 AND `+cond+`
+		 ORDER BY d.current_version_id IS NOT DISTINCT FROM v.id DESC, v.created_at
+		 LIMIT 1`,
+		append([]interface{}{sum}, args...)...).Scan(&id, &number)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return errFetchFailed
+	}
+
+	return errDuplicateFile.WithMessage(apierror.Textf(
+		"File yang sama sudah ada di dokumen %s versi %d",
+		"The same file already exists in document %s version %d", id, number)).
+		WithMeta(map[string]interface{}{"document_id": id, "version_number": number})
+}
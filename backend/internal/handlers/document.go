@@ -3,78 +3,66 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
+	"repository-un/internal/apierror"
 	"repository-un/internal/config"
-	"repository-un/internal/middleware"
+	"repository-un/internal/filetype"
+	"repository-un/internal/metrics"
 	"repository-un/internal/models"
+	"repository-un/internal/router"
+	"repository-un/internal/storage"
 	"repository-un/internal/utils"
+	"repository-un/internal/workflow"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-// DocumentsHandler menangani operasi list dan create dokumen
-// GET /api/documents - List semua dokumen
-// POST /api/documents - Upload dokumen baru
-func DocumentsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
+// listDocuments mengambil dokumen dari database dengan pagination, sorting dan filter
+// GET /api/documents
+// Query params:
+//   - page, limit        : halaman (mulai 1) dan jumlah per halaman (maks 100)
+//   - sort, order        : kolom sort (created_at, judul, penulis, jenis_file, status) dan asc/desc
+//   - status, jenis_file : filter nilai persis
+//   - penulis            : filter sebagian nama penulis (case-insensitive)
+//   - from, to           : rentang tanggal created_at (YYYY-MM-DD atau RFC3339)
+func listDocuments(w http.ResponseWriter, r *http.Request) {
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	middleware.EnableCORS(w)
-
-	switch r.Method {
-	case http.MethodGet:
-		listDocuments(w, r)
-	case http.MethodPost:
-		createDocument(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
 
-// DocumentByIdHandler menangani operasi pada dokumen tertentu
-// GET /api/documents/:id - Get dokumen by ID
-// PUT /api/documents/:id - Update dokumen
-// DELETE /api/documents/:id - Delete dokumen
-func DocumentByIdHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
-		return
-	}
-	middleware.EnableCORS(w)
+	where, args := params.where(currentViewer(r))
 
-	id := strings.TrimPrefix(r.URL.Path, "/api/documents/")
-	if id == "" {
-		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+	var total int
+	err = config.DB.QueryRow(r.Context(),
+		`SELECT COUNT(*) FROM documents`+where, args...).Scan(&total)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
 		return
 	}
 
-	switch r.Method {
-	case http.MethodGet:
-		getDocumentById(w, r, id)
-	case http.MethodPut:
-		updateDocument(w, r, id)
-	case http.MethodDelete:
-		deleteDocument(w, r, id)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-// listDocuments mengambil semua dokumen dari database
-func listDocuments(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query(context.Background(),
-		`SELECT id, judul, penulis, jenis_file, status, created_at
-		 FROM documents
-		 ORDER BY created_at DESC`)
+	args = append(args, params.Limit, (params.Page-1)*params.Limit)
+	rows, err := config.DB.Query(r.Context(),
+		fmt.Sprintf(`SELECT id, judul, penulis, jenis_file, mime_type, status, owner_id,
+		        processing_status, processing_error, created_at, updated_at
+		 FROM documents%s
+		 ORDER BY %s %s, id %s
+		 LIMIT $%d OFFSET $%d`,
+			where, params.Sort, params.Order, params.Order, len(args)-1, len(args)),
+		args...)
 	if err != nil {
-		http.Error(w, "Gagal mengambil data", http.StatusInternalServerError)
+		apierror.Write(w, r, errFetchFailed)
 		return
 	}
 	defer rows.Close()
@@ -88,36 +76,41 @@ func listDocuments(w http.ResponseWriter, r *http.Request) {
 			&d.Judul,
 			&d.Penulis,
 			&d.JenisFile,
+			&d.MimeType,
 			&d.Status,
+			&d.OwnerID,
+			&d.ProcessingStatus,
+			&d.ProcessingError,
 			&d.CreatedAt,
+			&d.UpdatedAt,
 		)
 		if err != nil {
-			http.Error(w, "Gagal membaca data", http.StatusInternalServerError)
+			apierror.Write(w, r, errReadFailed)
 			return
 		}
 		documents = append(documents, d)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(documents)
+	json.NewEncoder(w).Encode(models.DocumentList{
+		Data:       documents,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (total + params.Limit - 1) / params.Limit,
+	})
 }
 
 // getDocumentById mengambil dokumen berdasarkan ID
-func getDocumentById(w http.ResponseWriter, r *http.Request, id string) {
-	var d models.Document
-	err := config.DB.QueryRow(context.Background(),
-		`SELECT id, judul, penulis, jenis_file, status, created_at
-		 FROM documents WHERE id = $1`, id).Scan(
-		&d.ID,
-		&d.Judul,
-		&d.Penulis,
-		&d.JenisFile,
-		&d.Status,
-		&d.CreatedAt,
-	)
-
-	if err != nil {
-		http.Error(w, "Dokumen tidak ditemukan", http.StatusNotFound)
+// GET /api/documents/:id
+func getDocumentById(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	d, err := loadDocument(r.Context(), id)
+
+	// Draft milik orang lain diperlakukan seperti tidak ada
+	doc := documentAccess{ID: d.ID, OwnerID: d.OwnerID, Status: d.Status}
+	if err != nil || !currentViewer(r).canView(doc) {
+		apierror.Write(w, r, errDocumentNotFound)
 		return
 	}
 
@@ -126,226 +119,281 @@ func getDocumentById(w http.ResponseWriter, r *http.Request, id string) {
 }
 
 // createDocument membuat dokumen baru dengan upload file
+// POST /api/documents
+// User yang login menjadi pemilik dokumen
 func createDocument(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(10 << 20) // 10 MB
-
-	judul := r.FormValue("title")
-	penulis := r.FormValue("author")
-	jenisFile := r.FormValue("category")
-	status := r.FormValue("status")
+	if !requireLogin(w, r) {
+		return
+	}
 
-	if judul == "" || penulis == "" || jenisFile == "" {
-		http.Error(w, "Metadata tidak lengkap", http.StatusBadRequest)
+	if err := parseUploadForm(w, r); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if status == "" {
-		status = "draft"
+	req, err := parseDocumentRequest(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := normalizeMetadata(&req); err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "File tidak ditemukan", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(apierror.Required("file")))
 		return
 	}
 	defer file.Close()
 
-	ext := filepath.Ext(header.Filename)
-	storedName := uuid.New().String() + ext
-	filePath := "uploads/" + storedName
-
-	dst, err := os.Create(filePath)
+	stored, err := storeUpload(r.Context(), file, header)
 	if err != nil {
-		http.Error(w, "Gagal menyimpan file", http.StatusInternalServerError)
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := checkDuplicate(r.Context(), r, stored.SHA256); err != nil {
+		config.Storage.Remove(r.Context(), stored.Staging)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer dst.Close()
-
-	io.Copy(dst, file)
 
-	id := uuid.New()
+	// File dan data dokumen disimpan dalam satu transaksi yang tetap
+	// diselesaikan walaupun client memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
 
-	// Validasi PDF jika file adalah PDF
-	if strings.ToLower(ext) == ".pdf" {
-		if err := utils.ValidatePDF(filePath); err != nil {
-			os.Remove(filePath) // Hapus file corrupt
-			http.Error(w, "File PDF rusak atau tidak valid: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		// Split PDF per halaman untuk preview
-		splitDir := filepath.Join("uploads", "split", id.String())
-		if err := utils.SplitPDF(filePath, splitDir); err != nil {
-			fmt.Println("Gagal memecah PDF:", err)
-			// Lanjut saja, ini fitur tambahan
-		}
+	tx, err := beginFileTx(ctx, stored.Staging)
+	if err != nil {
+		apierror.Write(w, r, errSaveMetadataFailed)
+		return
 	}
+	defer tx.Rollback(ctx)
 
-	query := `
-		INSERT INTO documents (id, judul, penulis, jenis_file, file_path, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`
+	id, version, err := createDocumentWithFile(ctx, tx, r, req, stored)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errSaveMetadataFailed)
+		return
+	}
 
-	_, err = config.DB.Exec(context.Background(), query,
-		id, judul, penulis, jenisFile, filePath, status,
-	)
+	recordAudit(r, auditEvent{Action: auditDocumentCreate, TargetType: targetDocument, TargetID: id,
+		Details: map[string]interface{}{"title": req.Title, "file_name": stored.Name, "size": stored.Size}})
 
+	doc, err := loadDocument(ctx, id)
 	if err != nil {
-		http.Error(w, "Gagal menyimpan metadata", http.StatusInternalServerError)
+		apierror.Write(w, r, errFetchFailed)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":         id,
-		"judul":      judul,
-		"penulis":    penulis,
-		"jenis_file": jenisFile,
-		"status":     status,
-	})
+	json.NewEncoder(w).Encode(struct {
+		models.Document
+		VersionNumber int `json:"version_number"`
+	}{doc, version.VersionNumber})
 }
 
-// updateDocument mengupdate dokumen
-func updateDocument(w http.ResponseWriter, r *http.Request, id string) {
-	r.ParseMultipartForm(10 << 20) // 10 MB
-
-	judul := r.FormValue("title")
-	penulis := r.FormValue("author")
-	jenisFile := r.FormValue("category")
-	status := r.FormValue("status")
+// updateDocument mengupdate dokumen (hanya pemilik atau admin)
+// PUT /api/documents/:id
+func updateDocument(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := authorizeContentEdit(w, r, id); !ok {
+		return
+	}
 
-	if judul == "" || penulis == "" || jenisFile == "" {
-		http.Error(w, "Metadata tidak lengkap", http.StatusBadRequest)
+	if err := parseUploadForm(w, r); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if status == "" {
-		status = "draft"
+	req, err := parseDocumentRequest(r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := normalizeMetadata(&req); err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
 
-	// Cek apakah ada file baru
+	// Perubahan tetap diselesaikan walaupun client memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
+
+	// Cek apakah ada file baru (hanya untuk request multipart)
 	file, header, err := r.FormFile("file")
-	var filePath string
+	hasFile := err == nil
+	details := map[string]interface{}{"title": req.Title}
 
-	if err == nil {
-		// Ada file baru diupload
+	var stored storedFile
+	if hasFile {
+		// Ada file baru diupload, file lama tetap disimpan sebagai versi sebelumnya
 		defer file.Close()
 
-		// Ambil path file lama untuk dihapus
-		var oldFilePath string
-		config.DB.QueryRow(context.Background(),
-			`SELECT file_path FROM documents WHERE id = $1`, id).Scan(&oldFilePath)
-
-		// Hapus file lama
-		if oldFilePath != "" {
-			os.Remove(oldFilePath)
-		}
-
-		// Simpan file baru
-		ext := filepath.Ext(header.Filename)
-		storedName := uuid.New().String() + ext
-		filePath = "uploads/" + storedName
-
-		dst, err := os.Create(filePath)
+		stored, err = storeUpload(ctx, file, header)
 		if err != nil {
-			http.Error(w, "Gagal menyimpan file", http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
-		defer dst.Close()
-
-		io.Copy(dst, file)
-
-		// Validasi PDF jika file adalah PDF
-		if strings.ToLower(ext) == ".pdf" {
-			if err := utils.ValidatePDF(filePath); err != nil {
-				os.Remove(filePath) // Hapus file corrupt
-				http.Error(w, "File PDF rusak atau tidak valid: "+err.Error(), http.StatusBadRequest)
-				return
-			}
-
-			// Split PDF per halaman
-			splitDir := filepath.Join("uploads", "split", id)
-			if err := utils.SplitPDF(filePath, splitDir); err != nil {
-				fmt.Println("Gagal memecah PDF:", err)
-			}
+		if err := checkDuplicate(ctx, r, stored.SHA256); err != nil {
+			config.Storage.Remove(ctx, stored.Staging)
+			apierror.Write(w, r, err)
+			return
 		}
+	}
 
-		// Update dengan file baru
-		query := `
-			UPDATE documents
-			SET judul = $1, penulis = $2, jenis_file = $3, status = $4, file_path = $5
-			WHERE id = $6
-		`
-		_, err = config.DB.Exec(context.Background(), query,
-			judul, penulis, jenisFile, status, filePath, id)
+	// Versi baru dan metadata disimpan dalam satu transaksi
+	tx, err := beginFileTx(ctx, stored.Staging)
+	if err != nil {
+		apierror.Write(w, r, errUpdateDocumentFailed)
+		return
+	}
+	defer tx.Rollback(ctx)
 
-		if err != nil {
-			http.Error(w, "Gagal update dokumen", http.StatusInternalServerError)
-			return
-		}
-	} else {
-		// Tidak ada file baru, update metadata saja
-		query := `
-			UPDATE documents
-			SET judul = $1, penulis = $2, jenis_file = $3, status = $4
-			WHERE id = $5
-		`
-		_, err = config.DB.Exec(context.Background(), query,
-			judul, penulis, jenisFile, status, id)
+	if err := lockEditableDocument(ctx, tx, id); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
 
+	if hasFile {
+		version, err := addDocumentVersion(ctx, tx, r, id, stored)
 		if err != nil {
-			http.Error(w, "Gagal update dokumen", http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
+
+		details["version_number"] = version.VersionNumber
+		details["file_name"] = stored.Name
+		details["size"] = stored.Size
+	}
+
+	// Update metadata, status tidak ikut diubah (lihat transitionDocument)
+	if err := saveMetadata(ctx, tx, id, req); err != nil {
+		apierror.Write(w, r, errUpdateDocumentFailed)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errUpdateDocumentFailed)
+		return
+	}
+
+	recordAudit(r, auditEvent{Action: auditDocumentUpdate, TargetType: targetDocument, TargetID: id, Details: details})
+
+	doc, err := loadDocument(ctx, id)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":         id,
-		"judul":      judul,
-		"penulis":    penulis,
-		"jenis_file": jenisFile,
-		"status":     status,
-	})
+	json.NewEncoder(w).Encode(doc)
 }
 
-// deleteDocument menghapus dokumen dan filenya
-func deleteDocument(w http.ResponseWriter, r *http.Request, id string) {
-	// Ambil file path dari DB
-	var filePath string
-	err := config.DB.QueryRow(
-		context.Background(),
-		`SELECT file_path FROM documents WHERE id = $1`,
-		id,
-	).Scan(&filePath)
+// deleteDocument menghapus dokumen dan filenya (hanya pemilik atau admin)
+// DELETE /api/documents/:id
+func deleteDocument(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := authorizeEdit(w, r, id); !ok {
+		return
+	}
+
+	// Penghapusan tetap diselesaikan walaupun client memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
 
+	// File dihapus setelah baris dokumen terhapus, sehingga dokumen
+	// tidak pernah kehilangan filenya jika DELETE gagal
+	tx, err := beginFileTx(ctx)
 	if err != nil {
-		http.Error(w, "Dokumen tidak ditemukan", http.StatusNotFound)
+		apierror.Write(w, r, errDeleteFailed)
 		return
 	}
+	defer tx.Rollback(ctx)
 
-	// Hapus file fisik
-	if filePath != "" {
-		err = os.Remove(filePath)
-		if err != nil {
-			fmt.Printf("Warning: Failed to delete file %s: %v\n", filePath, err)
-		}
+	var title string
+	err = tx.QueryRow(ctx,
+		`SELECT judul FROM documents WHERE id = $1 FOR UPDATE`, id).Scan(&title)
+	if errors.Is(err, pgx.ErrNoRows) {
+		apierror.Write(w, r, errDocumentNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
 	}
 
-	// Hapus direktori split pages jika ada
-	splitDir := filepath.Join("uploads", "split", id)
-	os.RemoveAll(splitDir)
+	// File versi lama (sebelum blob) milik dokumen ini sendiri
+	rows, err := tx.Query(ctx,
+		`SELECT DISTINCT file_path FROM document_versions WHERE document_id = $1 AND sha256 IS NULL`, id)
+	if err != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
+	files := 0
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			apierror.Write(w, r, errDeleteFailed)
+			return
+		}
+		if p != "" {
+			tx.RemoveOnCommit(fileKey(p))
+			files++
+		}
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
 
-	// Hapus dari database
-	_, err = config.DB.Exec(
-		context.Background(),
-		`DELETE FROM documents WHERE id = $1`,
-		id,
-	)
+	// Kurangi referensi blob, blob yang tidak dipakai lagi ikut dihapus
+	rows, err = tx.Query(ctx,
+		`UPDATE blobs b SET ref_count = b.ref_count - v.refs
+		 FROM (SELECT sha256, COUNT(*) AS refs FROM document_versions
+		       WHERE document_id = $1 AND sha256 IS NOT NULL GROUP BY sha256) v
+		 WHERE b.sha256 = v.sha256
+		 RETURNING b.sha256, b.ref_count`, id)
 	if err != nil {
-		http.Error(w, "Gagal menghapus data", http.StatusInternalServerError)
+		apierror.Write(w, r, errDeleteFailed)
 		return
 	}
+	for rows.Next() {
+		var sum string
+		var refs int
+		if err := rows.Scan(&sum, &refs); err != nil {
+			rows.Close()
+			apierror.Write(w, r, errDeleteFailed)
+			return
+		}
+		files++
+		if refs == 0 {
+			tx.ReleaseOnCommit(sum)
+		}
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM documents WHERE id = $1`, id); err != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
+
+	// Direktori split pages semua versi
+	tx.RemoveAllOnCommit(splitDir(id))
+
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
+
+	recordAudit(r, auditEvent{Action: auditDocumentDelete, TargetType: targetDocument, TargetID: id,
+		Details: map[string]interface{}{"title": title, "files": files}})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message":"Dokumen berhasil dihapus"}`))
@@ -354,86 +402,164 @@ func deleteDocument(w http.ResponseWriter, r *http.Request, id string) {
 // DownloadHandler menangani download dokumen
 // GET /download/:id
 func DownloadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
+	id := r.PathValue("id")
+	if _, ok := authorizeView(w, r, id); !ok {
 		return
 	}
-	middleware.EnableCORS(w)
 
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Ambil file_path dari DB. Dokumen lama tanpa versi belum pernah dipindai.
+	var filePath, mimeType, scanStatus string
+	err := config.DB.QueryRow(
+		r.Context(),
+		`SELECT d.file_path, d.mime_type, COALESCE(v.scan_status, 'clean')
+		 FROM documents d LEFT JOIN document_versions v ON v.id = d.current_version_id
+		 WHERE d.id = $1`,
+		id,
+	).Scan(&filePath, &mimeType, &scanStatus)
+
+	if err != nil {
+		apierror.Write(w, r, errFileNotFound)
 		return
 	}
 
-	// Ambil ID dari URL
-	path := strings.TrimPrefix(r.URL.Path, "/download/")
-	id := strings.TrimSuffix(path, "/download")
-
-	if id == "" {
-		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+	// File baru bisa didownload setelah lolos pemindaian malware
+	if !checkScanStatus(w, r, scanStatus) {
 		return
 	}
 
-	// Ambil file_path dari DB
-	var filePath string
-	err := config.DB.QueryRow(
-		context.Background(),
-		`SELECT file_path FROM documents WHERE id = $1`,
-		id,
-	).Scan(&filePath)
-
+	f, info, err := config.Storage.Open(r.Context(), fileKey(filePath))
 	if err != nil {
-		http.Error(w, "File tidak ditemukan", http.StatusNotFound)
+		apierror.Write(w, r, errFileNotFound)
 		return
 	}
+	defer f.Close()
+
+	if isNewDownload(r) {
+		recordAudit(r, auditEvent{Action: auditDownload, TargetType: targetDocument, TargetID: id})
+	}
 
 	// Kirim file
+	setFileContentType(w, mimeType)
 	w.Header().Set("Content-Disposition", "attachment")
-	http.ServeFile(w, r, filePath)
+	http.ServeContent(w, r, filepath.Base(info.Key), info.ModTime, f)
+}
+
+// setFileContentType memakai MIME type hasil deteksi saat upload sebagai
+// Content-Type. File lama tanpa MIME type ditebak dari ekstensinya oleh
+// http.ServeContent.
+func setFileContentType(w http.ResponseWriter, mimeType string) {
+	if mimeType != "" {
+		w.Header().Set("Content-Type", mimeType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 }
 
 // PreviewSplitHandler menangani preview halaman PDF
 // GET /preview/split/:id/:page.pdf
+// GET /preview/split/:id/:version_id/:page.pdf
 func PreviewSplitHandler(w http.ResponseWriter, r *http.Request) {
-	middleware.EnableCORS(w)
+	id, page := r.PathValue("id"), r.PathValue("path")
+	relPath := id + "/" + page
+
+	// Halaman tanpa folder versi diambil dari versi aktif dokumen
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+	if !strings.Contains(page, "/") {
+		relPath = strings.TrimPrefix(currentSplitDir(r.Context(), id)+"/"+page, "split/")
+	}
+
+	serveSplitPage(w, r, relPath)
+}
+
+// PreviewImageHandler menyajikan gambar halaman dari versi aktif dokumen
+// GET /preview/image/:id/:page?size=thumb|medium
+// Nomor halaman dimulai dari 1, size default medium.
+func PreviewImageHandler(w http.ResponseWriter, r *http.Request) {
+	id, page := r.PathValue("id"), router.IntParam(r, "page")
+
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = utils.ImageSizeMedium
+	}
+	if _, ok := utils.ImageSizes[size]; !ok {
+		apierror.Write(w, r, errInvalidQuery.WithFields(apierror.Field("size", "invalid", "harus thumb atau medium", "must be thumb or medium")))
+		return
+	}
+
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	// Gambar lama bisa saja dibuat dengan format berbeda dari pengaturan sekarang
+	dir := currentSplitDir(r.Context(), id)
+	formats := []string{config.App.Preview.ImageFormat}
+	for format := range utils.ImageFormats {
+		if format != config.App.Preview.ImageFormat {
+			formats = append(formats, format)
+		}
+	}
+
+	for _, format := range formats {
+		f, info, err := config.Storage.Open(r.Context(), utils.ImageKey(dir, size, page, format))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Content-Type", utils.ImageFormats[format])
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		http.ServeContent(w, r, path.Base(info.Key), info.ModTime, f)
 		return
 	}
 
-	// Expected path: /preview/split/{id}/{page.pdf}
-	relPath := strings.TrimPrefix(r.URL.Path, "/preview/split/")
+	apierror.Write(w, r, errPageImageMissing)
+}
+
+// SplitFileHandler menyajikan file hasil split secara langsung
+// GET /split/:id/:page.pdf
+// GET /split/:id/:version_id/:page.pdf
+func SplitFileHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	relPath := id + "/" + r.PathValue("path")
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	serveSplitPage(w, r, relPath)
+}
+
+// serveSplitPage mengirim satu halaman hasil split dari storage
+func serveSplitPage(w http.ResponseWriter, r *http.Request, relPath string) {
 	if relPath == "" {
-		http.Error(w, "Path not specified", http.StatusBadRequest)
+		apierror.Write(w, r, errInvalidPath)
 		return
 	}
 
 	// Cegah directory traversal
-	cleanRel := filepath.Clean(relPath)
-	if strings.HasPrefix(cleanRel, "..") {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+	key, err := storage.CleanKey("split/" + relPath)
+	if err != nil || !strings.HasPrefix(key, "split/") {
+		apierror.Write(w, r, errInvalidPath)
 		return
 	}
 
-	filePath := filepath.Join("uploads", "split", cleanRel)
-
-	// Cek file exists
-	fileInfo, err := os.Stat(filePath)
+	f, info, err := config.Storage.Open(r.Context(), key)
 	if err != nil {
-		fmt.Printf("File not found: %s, error: %v\n", filePath, err)
-		http.Error(w, "File not found", http.StatusNotFound)
+		slog.DebugContext(r.Context(), "File halaman tidak ditemukan", "key", key, "error", err)
+		apierror.Write(w, r, errFileNotFound)
 		return
 	}
+	defer f.Close()
 
 	// Cek apakah file kosong
-	if fileInfo.Size() == 0 {
-		fmt.Printf("Warning: File is empty: %s\n", filePath)
-		http.Error(w, "File is empty", http.StatusInternalServerError)
+	if info.Size == 0 {
+		slog.WarnContext(r.Context(), "File halaman kosong", "key", key)
+		apierror.Write(w, r, errFileEmpty)
 		return
 	}
 
-	fmt.Printf("Serving file: %s (size: %d bytes)\n", filePath, fileInfo.Size())
+	slog.DebugContext(r.Context(), "Mengirim file halaman", "key", key, "size", info.Size)
 
 	// Set headers
 	w.Header().Set("Content-Type", "application/pdf")
@@ -441,40 +567,19 @@ func PreviewSplitHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Header().Set("Cache-Control", "public, max-age=3600")
 
-	http.ServeFile(w, r, filePath)
+	http.ServeContent(w, r, path.Base(key), info.ModTime, f)
 }
 
-// DocumentPagesHandler mengembalikan list halaman PDF
-// GET /api/documents/pages/:id
+// DocumentPagesHandler mengembalikan list halaman PDF dari versi aktif
+// GET /api/documents/:id/pages
 func DocumentPagesHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
+	id := r.PathValue("id")
+	if _, ok := authorizeView(w, r, id); !ok {
 		return
 	}
-	middleware.EnableCORS(w)
 
-	// Parse ID: /api/documents/pages/{id}
-	id := strings.TrimPrefix(r.URL.Path, "/api/documents/pages/")
-	if id == "" {
-		http.Error(w, "ID tidak valid", http.StatusBadRequest)
-		return
-	}
-
-	splitDir := filepath.Join("uploads", "split", id)
-	files, err := os.ReadDir(splitDir)
-	if err != nil {
-		// Dir tidak ditemukan = belum di-split atau bukan PDF
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte("[]"))
-		return
-	}
-
-	var pages []string
-	for _, f := range files {
-		if !f.IsDir() && strings.HasSuffix(f.Name(), ".pdf") {
-			pages = append(pages, f.Name())
-		}
-	}
+	// Dir kosong = belum di-split atau bukan PDF
+	pages := listPages(r.Context(), currentSplitDir(r.Context(), id))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(pages)
@@ -483,14 +588,11 @@ func DocumentPagesHandler(w http.ResponseWriter, r *http.Request) {
 // UploadHandler menangani upload file legacy
 // POST /uploads
 func UploadHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
+	if !requireLogin(w, r) {
 		return
 	}
-	middleware.EnableCORS(w)
-
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if err := parseUploadForm(w, r); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -498,55 +600,211 @@ func UploadHandler(w http.ResponseWriter, r *http.Request) {
 	penulis := r.FormValue("penulis")
 	jenisFile := r.FormValue("jenis_file")
 
-	if judul == "" || penulis == "" || jenisFile == "" {
-		http.Error(w, "Metadata tidak lengkap", http.StatusBadRequest)
+	req := models.CreateDocumentRequest{Title: judul, Author: penulis, Category: jenisFile}
+	if err := normalizeMetadata(&req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "File tidak ditemukan", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(apierror.Required("file")))
 		return
 	}
 	defer file.Close()
 
-	ext := filepath.Ext(header.Filename)
-	storedName := uuid.New().String() + ext
-	filePath := "uploads/" + storedName
+	stored, err := storeUpload(r.Context(), file, header)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := checkDuplicate(r.Context(), r, stored.SHA256); err != nil {
+		config.Storage.Remove(r.Context(), stored.Staging)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// File dan data dokumen disimpan dalam satu transaksi yang tetap
+	// diselesaikan walaupun client memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
 
-	dst, err := os.Create(filePath)
+	tx, err := beginFileTx(ctx, stored.Staging)
 	if err != nil {
-		http.Error(w, "Gagal menyimpan file", http.StatusInternalServerError)
+		apierror.Write(w, r, errSaveMetadataFailed)
 		return
 	}
-	defer dst.Close()
+	defer tx.Rollback(ctx)
 
-	io.Copy(dst, file)
+	id, _, err := createDocumentWithFile(ctx, tx, r, req, stored)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errSaveMetadataFailed)
+		return
+	}
+
+	recordAudit(r, auditEvent{Action: auditDocumentCreate, TargetType: targetDocument, TargetID: id,
+		Details: map[string]interface{}{"title": req.Title, "file_name": stored.Name, "size": stored.Size}})
 
-	id := uuid.New()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"id":         id,
+		"judul":      judul,
+		"penulis":    penulis,
+		"jenis_file": jenisFile,
+		"status":     "draft",
+	})
+}
+
+// storedFile adalah file upload yang sudah tersimpan di area staging
+type storedFile struct {
+	Path     string // lokasi blob di storage setelah versi dicatat, lihat fileKey
+	Staging  string // lokasi sementara sampai versi dicatat
+	Name     string // nama file asli dari client
+	Size     int64
+	MimeType string // hasil deteksi isi file
+	SHA256   string
+}
 
-	query := `
-		INSERT INTO documents (id, judul, penulis, jenis_file, file_path, status)
-		VALUES ($1, $2, $3, $4, $5, 'draft')
-	`
+// formOverhead adalah ruang untuk field metadata dan header multipart di
+// luar isi file
+const formOverhead = 1 << 20
 
-	_, err = config.DB.Exec(context.Background(), query,
-		id, judul, penulis, jenisFile, filePath,
-	)
+// parseUploadForm membaca form multipart upload file. Body dibatasi file
+// terbesar yang diizinkan ditambah formOverhead agar client tidak bisa
+// mengirim body tanpa batas ke memori atau file sementara.
+func parseUploadForm(w http.ResponseWriter, r *http.Request) error {
+	maxSize := maxAllowedTypeSize()
+	r.Body = http.MaxBytesReader(w, r.Body, int64(maxSize)+formOverhead)
 
+	// Error lain (misalnya body JSON) ditangani saat field form dibaca
+	var tooLarge *http.MaxBytesError
+	if err := r.ParseMultipartForm(int64(config.App.Upload.MaxMemory)); errors.As(err, &tooLarge) {
+		return errUploadTooLarge.WithMessage(apierror.Textf(
+			"Ukuran file maksimal %s", "Maximum file size is %s", maxSize))
+	}
+	return nil
+}
+
+// maxAllowedTypeSize mengembalikan batas ukuran terbesar dari semua format
+// yang diizinkan. Batas per format dicek setelah format file dikenali.
+func maxAllowedTypeSize() config.ByteSize {
+	var maxSize config.ByteSize
+	for _, name := range config.App.Upload.AllowedTypes {
+		maxSize = max(maxSize, config.App.Upload.TypeMaxSize.For(name))
+	}
+	return maxSize
+}
+
+// storeUpload menyimpan file upload multipart ke area staging.
+// Ekstensi dan MIME type diambil dari isi file, bukan dari client.
+func storeUpload(ctx context.Context, file multipart.File, header *multipart.FileHeader) (storedFile, error) {
+	t, err := detectFileType(file, header.Size)
 	if err != nil {
-		http.Error(w, "Gagal menyimpan metadata", http.StatusInternalServerError)
-		return
+		return storedFile{}, err
 	}
 
-	r.ParseMultipartForm(10 << 20) // 10 MB
+	f, err := stageFile(ctx, file, header.Filename, header.Size, t)
+	if err != nil {
+		return f, errStoreFileFailed
+	}
+	metrics.UploadSize.Observe(float64(f.Size))
+	return f, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{
-		"id": "%s",
-		"judul": "%s",
-		"penulis": "%s",
-		"jenis_file": "%s",
-		"status": "draft"
-	}`, id, judul, penulis, jenisFile)
+// detectFileType mengenali format file dari isinya lalu mengecek
+// allow-list dan ukuran maksimal format tersebut
+func detectFileType(r io.ReaderAt, size int64) (filetype.Type, error) {
+	allowed := config.App.Upload.AllowedTypes
+
+	t, err := filetype.Detect(r, size)
+	if errors.Is(err, filetype.ErrUnknown) || (err == nil && !config.App.Upload.Allows(t.Name)) {
+		return t, errUnsupportedFileType.WithMessage(apierror.Textf(
+			"Format file tidak didukung, format yang diizinkan: %s",
+			"Unsupported file format, allowed formats: %s", strings.Join(allowed, ", ")))
+	}
+	if err != nil {
+		return t, errReadFailed
+	}
+
+	if max := config.App.Upload.TypeMaxSize.For(t.Name); size > int64(max) {
+		return t, errUploadTooLarge.WithMessage(apierror.Textf(
+			"Ukuran file %s maksimal %s", "Maximum size for %s files is %s", strings.ToUpper(t.Name), max))
+	}
+	return t, nil
+}
+
+// createDocumentWithFile menyimpan dokumen draft baru milik user yang login
+// di dalam tx dengan f sebagai versi 1, mencatat riwayat status lalu
+// menjadwalkan pemrosesan file. Mengembalikan id dokumen dan versinya.
+// Tidak ada yang tersimpan, termasuk file, sampai tx di-commit.
+func createDocumentWithFile(ctx context.Context, tx *fileTx, r *http.Request, req models.CreateDocumentRequest, f storedFile) (string, models.DocumentVersion, error) {
+	id := uuid.New().String()
+	version := newVersion(r, id, f)
+
+	if err := insertDocument(ctx, tx, id, f.Path, currentViewer(r).UserID, req); err != nil {
+		return "", version, errSaveMetadataFailed
+	}
+
+	// File pertama dicatat sebagai versi 1
+	if err := saveVersion(ctx, tx, &version, f.Staging); err != nil {
+		return "", version, errSaveVersionFailed
+	}
+
+	// Dokumen baru selalu draft, status hanya berubah lewat endpoint workflow
+	_, err := recordStatusChange(ctx, tx, id, actionCreate,
+		nil, workflow.StatusDraft, currentViewer(r).UserID, "")
+	if err != nil {
+		return "", version, errHistoryFailed
+	}
+
+	// Validasi, split dan index teks PDF berjalan di background
+	if err := enqueueProcessing(ctx, tx, id, version.ID); err != nil {
+		return "", version, errScheduleFailed
+	}
+	return id, version, nil
+}
+
+// addDocumentVersion melampirkan f sebagai versi baru dokumen id di dalam
+// tx lalu menjadwalkan pemrosesannya. File lama tetap disimpan sebagai
+// versi sebelumnya.
+func addDocumentVersion(ctx context.Context, tx *fileTx, r *http.Request, id string, f storedFile) (models.DocumentVersion, error) {
+	version := newVersion(r, id, f)
+
+	// Catat versi baru, file_path dokumen ikut diperbarui
+	if err := saveVersion(ctx, tx, &version, f.Staging); err != nil {
+		return version, errSaveVersionFailed
+	}
+
+	// File baru diproses ulang di background
+	if err := enqueueProcessing(ctx, tx, id, version.ID); err != nil {
+		return version, errScheduleFailed
+	}
+	return version, nil
+}
+
+// insertDocument menyimpan dokumen baru berstatus draft beserta metadatanya
+func insertDocument(ctx context.Context, tx pgx.Tx, id, filePath, ownerID string, req models.CreateDocumentRequest) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO documents (id, judul, penulis, jenis_file, file_path, status, owner_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		id, req.Title, strings.Join(req.Authors, ", "), req.Category, filePath, workflow.StatusDraft, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return saveMetadata(ctx, tx, id, req)
+}
+
+// fileKey mengubah file_path di database menjadi key storage.
+// Data lama menyimpan path lengkap "uploads/<nama file>".
+func fileKey(filePath string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filePath), "uploads/")
+}
+
+// splitDir mengembalikan lokasi halaman hasil split untuk dokumen
+func splitDir(id string) string {
+	return storage.Join("split", id)
 }
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository-un/internal/apierror"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	// maxOffset membatasi (page-1)*limit agar OFFSET tidak overflow dan
+	// query tidak memindai terlalu banyak baris
+	maxOffset = 10000
+)
+
+// sortColumns adalah kolom yang boleh dipakai untuk sorting list dokumen.
+// Nilai dari query string tidak pernah dimasukkan langsung ke SQL.
+var sortColumns = map[string]string{
+	"created_at": "created_at",
+	"judul":      "judul",
+	"penulis":    "penulis",
+	"jenis_file": "jenis_file",
+	"status":     "status",
+}
+
+// listParams berisi parameter list dokumen yang sudah divalidasi
+type listParams struct {
+	Page      int
+	Limit     int
+	Sort      string
+	Order     string
+	Status    string
+	JenisFile string
+	Penulis   string
+	From      *time.Time
+	To        *time.Time
+}
+
+// parseListParams membaca dan memvalidasi query string list dokumen
+func parseListParams(q url.Values) (listParams, error) {
+	p := listParams{
+		Sort:      "created_at",
+		Order:     "DESC",
+		Status:    q.Get("status"),
+		JenisFile: q.Get("jenis_file"),
+		Penulis:   strings.TrimSpace(q.Get("penulis")),
+	}
+
+	var err error
+	p.Page, p.Limit, err = parsePagination(q)
+	if err != nil {
+		return p, err
+	}
+
+	if v := q.Get("sort"); v != "" {
+		column, ok := sortColumns[v]
+		if !ok {
+			return p, errInvalidQuery.WithFields(apierror.Field("sort", "invalid", "kolom %q tidak dikenal", "unknown column %q", v))
+		}
+		p.Sort = column
+	}
+
+	switch strings.ToLower(q.Get("order")) {
+	case "", "desc":
+		p.Order = "DESC"
+	case "asc":
+		p.Order = "ASC"
+	default:
+		return p, errInvalidQuery.WithFields(apierror.Field("order", "invalid", "harus asc atau desc", "must be asc or desc"))
+	}
+
+	if v := q.Get("from"); v != "" {
+		from, err := parseDateParam(v, false)
+		if err != nil {
+			return p, errInvalidQuery.WithFields(apierror.Invalid("from"))
+		}
+		p.From = &from
+	}
+
+	if v := q.Get("to"); v != "" {
+		to, err := parseDateParam(v, true)
+		if err != nil {
+			return p, errInvalidQuery.WithFields(apierror.Invalid("to"))
+		}
+		p.To = &to
+	}
+
+	return p, nil
+}
+
+// parsePagination membaca parameter page dan limit
+func parsePagination(q url.Values) (page int, limit int, err error) {
+	page, limit = 1, defaultPageSize
+
+	if v := q.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, errInvalidQuery.WithFields(apierror.Invalid("page"))
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return 0, 0, errInvalidQuery.WithFields(apierror.Invalid("limit"))
+		}
+		if limit > maxPageSize {
+			limit = maxPageSize
+		}
+	}
+
+	if page-1 > maxOffset/limit {
+		return 0, 0, errInvalidQuery.WithFields(apierror.Field("page", "too_large",
+			"terlalu besar, paling banyak %d data pertama yang bisa dibuka", "too large, only the first %d items can be paged", maxOffset))
+	}
+
+	return page, limit, nil
+}
+
+// where menyusun klausa WHERE beserta argumennya.
+// Dokumen yang tidak boleh dilihat oleh v selalu disaring.
+func (p listParams) where(v viewer) (string, []interface{}) {
+	visible, args := v.visibleCondition("", 1)
+	conditions := []string{visible}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if p.Status != "" {
+		add("status = $%d", p.Status)
+	}
+	if p.JenisFile != "" {
+		add("jenis_file = $%d", p.JenisFile)
+	}
+	if p.Penulis != "" {
+		add("penulis ILIKE $%d", "%"+escapeLike(p.Penulis)+"%")
+	}
+	if p.From != nil {
+		add("created_at >= $%d", *p.From)
+	}
+	if p.To != nil {
+		add("created_at < $%d", *p.To)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// parseDateParam menerima tanggal YYYY-MM-DD atau RFC3339.
+// Untuk batas akhir (end), tanggal tanpa jam dianggap sampai akhir hari itu.
+func parseDateParam(v string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// escapeLike meng-escape karakter wildcard pada pola LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
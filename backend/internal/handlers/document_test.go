@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+)
+
+func TestParseUploadForm(t *testing.T) {
+	old := config.App.Upload
+	t.Cleanup(func() { config.App.Upload = old })
+	config.App.Upload.AllowedTypes = []string{"pdf", "image"}
+	config.App.Upload.TypeMaxSize.PDF = 2 * config.MB
+	config.App.Upload.TypeMaxSize.Image = 1 * config.MB
+	config.App.Upload.TypeMaxSize.ZIP = 100 * config.MB // tidak diizinkan, tidak dihitung
+
+	tests := []struct {
+		name     string
+		fileSize int
+		wantErr  bool
+	}{
+		{"within limit", 2 * int(config.MB), false},
+		{"over limit", 2*int(config.MB) + formOverhead + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			mw.WriteField("title", "Skripsi")
+			fw, _ := mw.CreateFormFile("file", "a.pdf")
+			fw.Write(bytes.Repeat([]byte("x"), tt.fileSize))
+			mw.Close()
+
+			r := httptest.NewRequest(http.MethodPost, "/api/documents", &body)
+			r.Header.Set("Content-Type", mw.FormDataContentType())
+
+			err := parseUploadForm(httptest.NewRecorder(), r)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("parseUploadForm: %v", err)
+				}
+				if r.FormValue("title") != "Skripsi" {
+					t.Errorf("title = %q", r.FormValue("title"))
+				}
+				return
+			}
+
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != errUploadTooLarge.Code {
+				t.Errorf("parseUploadForm error = %v, want %s", err, errUploadTooLarge.Code)
+			}
+		})
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantPage  int
+		wantLimit int
+		wantField string
+	}{
+		{"", 1, defaultPageSize, ""},
+		{"page=3&limit=50", 3, 50, ""},
+		{"limit=1000", 1, maxPageSize, ""},
+		{"page=101&limit=100", 101, 100, ""},
+		{"page=102&limit=100", 0, 0, "page"},
+		{"page=10001&limit=1", 10001, 1, ""},
+		{"page=10002&limit=1", 0, 0, "page"},
+		{"page=9223372036854775807&limit=100", 0, 0, "page"},
+		{"page=99999999999999999999", 0, 0, "page"},
+		{"page=0", 0, 0, "page"},
+		{"limit=-5", 0, 0, "limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			page, limit, err := parsePagination(q)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("parsePagination: %v", err)
+				}
+				if page != tt.wantPage || limit != tt.wantLimit {
+					t.Errorf("parsePagination = %d, %d, want %d, %d", page, limit, tt.wantPage, tt.wantLimit)
+				}
+				return
+			}
+
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) || apiErr.Code != errInvalidQuery.Code {
+				t.Fatalf("error = %v, want %s", err, errInvalidQuery.Code)
+			}
+			if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != tt.wantField {
+				t.Errorf("fields = %+v, want %s", apiErr.Fields, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"repository-un/internal/apierror"
+)
+
+// Error yang dikirim handler ke client. Kode (argumen kedua) adalah bagian
+// dari API dan tidak boleh diubah; pesan boleh diperbaiki kapan saja.
+var (
+	// Dokumen dan file
+	errDocumentNotFound   = apierror.New(http.StatusNotFound, "document_not_found", "Dokumen tidak ditemukan", "Document not found")
+	errDocumentForbidden  = apierror.New(http.StatusForbidden, "document_forbidden", "Anda tidak memiliki akses untuk mengubah dokumen ini", "You are not allowed to modify this document")
+	errDocumentLocked     = apierror.New(http.StatusConflict, "document_locked", "Dokumen yang sedang direview atau sudah terbit tidak dapat diubah", "Documents in review or published cannot be modified")
+	errDocumentProcessing = apierror.New(http.StatusConflict, "document_processing", "Dokumen sedang diproses", "Document is being processed")
+	errVersionNotFound    = apierror.New(http.StatusNotFound, "version_not_found", "Versi tidak ditemukan", "Version not found")
+	errFileNotFound       = apierror.New(http.StatusNotFound, "file_not_found", "File tidak ditemukan", "File not found")
+	errFileEmpty          = apierror.New(http.StatusInternalServerError, "file_empty", "File kosong", "File is empty")
+	errInvalidPath        = apierror.New(http.StatusBadRequest, "invalid_path", "Path tidak valid", "Invalid path")
+	errFileScanPending    = apierror.New(http.StatusConflict, "file_scan_pending", "File masih diperiksa, coba lagi nanti", "File is still being scanned, try again later")
+	errFileQuarantined    = apierror.New(http.StatusForbidden, "file_quarantined", "File dikarantina karena terdeteksi malware atau tidak dapat dipindai", "File is quarantined because malware was detected or it could not be scanned")
+	errDuplicateFile      = apierror.New(http.StatusConflict, "duplicate_file", "File yang sama sudah pernah diupload", "The same file has already been uploaded")
+	errPageImageMissing   = apierror.New(http.StatusNotFound, "page_image_unavailable", "Gambar halaman belum tersedia", "Page image is not available yet")
+	errInvalidMetadata    = apierror.ValidationFailed.WithMessage(apierror.Text{ID: "Metadata tidak valid", EN: "Invalid metadata"})
+	errInvalidQuery       = apierror.ValidationFailed.WithMessage(apierror.Text{ID: "Parameter query tidak valid", EN: "Invalid query parameter"})
+
+	// Upload bertahap
+	errUploadNotFound      = apierror.New(http.StatusNotFound, "upload_not_found", "Sesi upload tidak ditemukan atau sudah kedaluwarsa", "Upload session not found or expired")
+	errUploadTooLarge      = apierror.New(http.StatusRequestEntityTooLarge, "upload_too_large", "Ukuran file melebihi batas", "File size exceeds the limit")
+	errUnsupportedFileType = apierror.New(http.StatusUnsupportedMediaType, "unsupported_file_type", "Format file tidak didukung", "Unsupported file format")
+	errUploadCompleting    = apierror.New(http.StatusConflict, "upload_completing", "Upload sedang diselesaikan", "Upload is being completed")
+	errUploadIncomplete    = apierror.New(http.StatusConflict, "upload_incomplete", "Masih ada potongan file yang belum diupload", "Some chunks have not been uploaded yet")
+	errUploadChecksum      = apierror.New(http.StatusUnprocessableEntity, "upload_checksum_mismatch", "Checksum file tidak cocok, upload ulang potongan file", "File checksum does not match, upload the chunks again")
+	errChunkOutOfRange     = apierror.New(http.StatusBadRequest, "chunk_out_of_range", "Nomor potongan melebihi jumlah potongan", "Chunk number exceeds the number of chunks")
+	errChunkSizeMismatch   = apierror.New(http.StatusBadRequest, "chunk_size_mismatch", "Ukuran potongan tidak sesuai", "Chunk size does not match")
+	errChunkChecksum       = apierror.New(http.StatusBadRequest, "chunk_checksum_mismatch", "Checksum potongan tidak cocok", "Chunk checksum does not match")
+
+	// Alur status dokumen
+	errTransitionForbidden = apierror.New(http.StatusForbidden, "transition_forbidden", "Anda tidak memiliki hak untuk aksi ini", "You are not allowed to perform this action")
+	errInvalidTransition   = apierror.New(http.StatusConflict, "invalid_transition", "Aksi tidak dapat dilakukan pada status dokumen sekarang", "Action is not allowed for the current document status")
+
+	// Auth dan user
+	errInvalidCredentials = apierror.New(http.StatusUnauthorized, "invalid_credentials", "Email atau password salah", "Invalid email or password")
+	errRefreshTokenDenied = apierror.New(http.StatusUnauthorized, "invalid_refresh_token", "Refresh token tidak valid atau sudah kedaluwarsa", "Invalid or expired refresh token")
+	errEmailTaken         = apierror.New(http.StatusConflict, "email_taken", "Email sudah terdaftar", "Email already registered")
+	errUserNotFound       = apierror.New(http.StatusNotFound, "user_not_found", "User tidak ditemukan", "User not found")
+	errCannotDeleteSelf   = apierror.New(http.StatusForbidden, "cannot_delete_self", "Tidak bisa menghapus akun sendiri", "Cannot delete your own account")
+)
+
+// internalError membuat error 500 dengan pesan yang menjelaskan operasi
+// yang gagal tanpa membocorkan detail internal
+func internalError(id, en string) *apierror.Error {
+	return apierror.Internal.WithMessage(apierror.Text{ID: id, EN: en})
+}
+
+// Error 500 per operasi
+var (
+	errFetchFailed          = internalError("Gagal mengambil data", "Failed to fetch data")
+	errReadFailed           = internalError("Gagal membaca data", "Failed to read data")
+	errStoreFileFailed      = internalError("Gagal menyimpan file", "Failed to store file")
+	errSaveMetadataFailed   = internalError("Gagal menyimpan metadata", "Failed to save metadata")
+	errSaveVersionFailed    = internalError("Gagal menyimpan versi dokumen", "Failed to save document version")
+	errUpdateDocumentFailed = internalError("Gagal update dokumen", "Failed to update document")
+	errDeleteFailed         = internalError("Gagal menghapus data", "Failed to delete data")
+	errScheduleFailed       = internalError("Gagal menjadwalkan pemrosesan dokumen", "Failed to schedule document processing")
+	errSearchFailed         = internalError("Gagal mencari dokumen", "Failed to search documents")
+	errRestoreFailed        = internalError("Gagal memulihkan versi", "Failed to restore version")
+	errStatusChangeFailed   = internalError("Gagal mengubah status", "Failed to change status")
+	errHistoryFailed        = internalError("Gagal mencatat riwayat status", "Failed to record status history")
+	errTokenFailed          = internalError("Gagal membuat token", "Failed to generate token")
+	errRefreshFailed        = internalError("Gagal memperbarui token", "Failed to refresh token")
+	errLogoutFailed         = internalError("Gagal logout", "Failed to logout")
+	errPasswordFailed       = internalError("Gagal memproses password", "Failed to process password")
+	errCreateUserFailed     = internalError("Gagal membuat user", "Failed to create user")
+	errUpdateUserFailed     = internalError("Gagal mengubah user", "Failed to update user")
+	errDeleteUserFailed     = internalError("Gagal menghapus user", "Failed to delete user")
+	errRevokeSessionsFailed = internalError("Gagal mencabut sesi user", "Failed to revoke user sessions")
+	errUploadFailed         = internalError("Gagal memproses upload", "Failed to process upload")
+)
+
+// missingFields mengembalikan kesalahan untuk setiap field wajib yang
+// kosong. pairs berisi nama field diikuti nilainya.
+func missingFields(pairs ...string) []apierror.FieldError {
+	var fields []apierror.FieldError
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			fields = append(fields, apierror.Required(pairs[i]))
+		}
+	}
+	return fields
+}
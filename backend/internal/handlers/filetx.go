@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"repository-un/internal/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fileTx adalah transaksi database yang juga mencatat perubahan file di
+// storage agar file dan baris database tidak saling tertinggal:
+//   - file staging selalu dihapus saat transaksi selesai,
+//   - file baru dihapus jika transaksi dibatalkan,
+//   - file lama baru dihapus setelah commit berhasil.
+//
+// File baru dihapus sebelum rollback, selagi kunci baris masih dipegang.
+// Jika commit sendiri gagal, file baru dibiarkan dan dibersihkan oleh
+// "server gc".
+type fileTx struct {
+	pgx.Tx
+	staged   []string
+	created  []string
+	obsolete []string
+	dirs     []string
+	blobs    []string
+	done     bool
+}
+
+// beginFileTx memulai fileTx. staged adalah file staging milik transaksi,
+// langsung dihapus jika transaksi gagal dimulai.
+func beginFileTx(ctx context.Context, staged ...string) (*fileTx, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		removeFiles(ctx, staged)
+		return nil, err
+	}
+	return &fileTx{Tx: tx, staged: staged}, nil
+}
+
+// Created mencatat file yang ditulis di dalam transaksi
+func (t *fileTx) Created(key string) {
+	t.created = append(t.created, key)
+}
+
+// RemoveOnCommit menjadwalkan penghapusan file setelah commit
+func (t *fileTx) RemoveOnCommit(key string) {
+	t.obsolete = append(t.obsolete, key)
+}
+
+// RemoveAllOnCommit menjadwalkan penghapusan direktori setelah commit
+func (t *fileTx) RemoveAllOnCommit(dir string) {
+	t.dirs = append(t.dirs, dir)
+}
+
+// ReleaseOnCommit menjadwalkan penghapusan blob yang tidak dipakai lagi
+// setelah commit, lihat releaseBlob
+func (t *fileTx) ReleaseOnCommit(sum string) {
+	t.blobs = append(t.blobs, sum)
+}
+
+// Commit menyimpan transaksi lalu menghapus file staging dan file lama
+func (t *fileTx) Commit(ctx context.Context) error {
+	t.done = true
+	err := t.Tx.Commit(ctx)
+	removeFiles(ctx, t.staged)
+	if err != nil {
+		return err
+	}
+
+	removeFiles(ctx, t.obsolete)
+	for _, dir := range t.dirs {
+		if err := config.Storage.RemoveAll(ctx, dir); err != nil {
+			slog.WarnContext(ctx, "Gagal menghapus direktori", "dir", dir, "error", err)
+		}
+	}
+	releaseBlobs(ctx, t.blobs)
+	return nil
+}
+
+// Rollback membatalkan transaksi beserta file baru dan file staging.
+// Tidak melakukan apa-apa setelah Commit, sehingga aman dipanggil lewat defer.
+func (t *fileTx) Rollback(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	removeFiles(ctx, t.created)
+	removeFiles(ctx, t.staged)
+	return t.Tx.Rollback(ctx)
+}
+
+// removeFiles menghapus file di storage, kegagalan hanya dicatat ke log
+func removeFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := config.Storage.Remove(ctx, key); err != nil {
+			slog.WarnContext(ctx, "Gagal menghapus file", "key", key, "error", err)
+		}
+	}
+}
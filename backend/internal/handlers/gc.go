@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"repository-un/internal/config"
+	"repository-un/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// GCOptions mengatur CollectGarbage
+type GCOptions struct {
+	// DryRun hanya melaporkan tanpa menghapus atau mengubah apa pun
+	DryRun bool
+
+	// MinAge melindungi file yang baru ditulis oleh upload atau
+	// pemrosesan yang sedang berjalan. File yatim yang lebih muda
+	// dilewati.
+	MinAge time.Duration
+}
+
+// MissingFile adalah file yang dicatat di database tetapi tidak ada di storage
+type MissingFile struct {
+	DocumentID    string
+	VersionNumber int // 0 untuk file_path dokumen yang tidak tercatat sebagai versi
+	Key           string
+}
+
+// GCReport adalah hasil CollectGarbage
+type GCReport struct {
+	Scanned        int                  // jumlah file di storage
+	Orphans        []storage.ObjectInfo // file tanpa baris database
+	Removed        int                  // file yatim yang berhasil dihapus
+	Skipped        int                  // file yatim yang lebih muda dari MinAge
+	Missing        []MissingFile
+	RefCountsFixed int // blob yang ref_count-nya dibetulkan
+	BlobsReleased  int // blob tanpa referensi yang dihapus
+}
+
+// gcRefs berisi semua yang dirujuk database, dibaca setelah isi storage
+// didaftar agar file baru tidak dianggap yatim
+type gcRefs struct {
+	documents  map[string]bool
+	versions   map[string]bool
+	sessions   map[string]bool
+	files      map[string]bool // file dokumen dan versi, termasuk blob
+	blobs      map[string]bool
+	referenced []MissingFile // semua file yang dirujuk, dicek keberadaannya
+}
+
+// CollectGarbage mencocokkan isi storage dengan tabel documents,
+// document_versions, blobs dan upload_sessions. File yatim (file dokumen
+// atau blob yang tidak dirujuk, folder split dokumen/versi yang sudah
+// dihapus, potongan sesi upload yang sudah tidak ada dan sisa staging)
+// dihapus. File yang dirujuk database tetapi tidak ada hanya dilaporkan.
+// Dijalankan lewat "server gc", aman dijalankan selagi server berjalan.
+func CollectGarbage(ctx context.Context, opts GCOptions) (GCReport, error) {
+	var report GCReport
+
+	if err := fixBlobRefCounts(ctx, opts.DryRun, &report); err != nil {
+		return report, err
+	}
+
+	objects, err := config.Storage.List(ctx, "")
+	if err != nil {
+		return report, err
+	}
+	report.Scanned = len(objects)
+
+	refs, err := loadGCRefs(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	cutoff := time.Now().Add(-opts.MinAge)
+	stored := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		stored[obj.Key] = true
+		if !refs.isOrphan(obj.Key) {
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, obj)
+		if obj.ModTime.After(cutoff) {
+			report.Skipped++
+			continue
+		}
+		if opts.DryRun {
+			continue
+		}
+		if err := config.Storage.Remove(ctx, obj.Key); err != nil {
+			slog.WarnContext(ctx, "Gagal menghapus file yatim", "key", obj.Key, "error", err)
+			continue
+		}
+		report.Removed++
+	}
+
+	// File yang dicatat setelah storage didaftar dicek ulang satu per satu
+	for _, m := range refs.referenced {
+		if stored[m.Key] {
+			continue
+		}
+		if _, err := config.Storage.Stat(ctx, m.Key); !errors.Is(err, storage.ErrNotExist) {
+			continue
+		}
+		slog.WarnContext(ctx, "File dokumen tidak ditemukan", "document_id", m.DocumentID,
+			"version_number", m.VersionNumber, "key", m.Key)
+		report.Missing = append(report.Missing, m)
+	}
+
+	return report, nil
+}
+
+// fixBlobRefCounts menyamakan ref_count blob dengan jumlah versi yang
+// memakainya lalu menghapus blob tanpa referensi. Setiap blob dihitung
+// ulang sambil memegang kunci barisnya, sama seperti registerBlob.
+func fixBlobRefCounts(ctx context.Context, dryRun bool, report *GCReport) error {
+	rows, err := config.DB.Query(ctx,
+		`SELECT b.sha256 FROM blobs b
+		 WHERE b.ref_count <> (SELECT COUNT(*) FROM document_versions v WHERE v.sha256 = b.sha256)`)
+	if err != nil {
+		return err
+	}
+	var sums []string
+	for rows.Next() {
+		var sum string
+		if err := rows.Scan(&sum); err != nil {
+			rows.Close()
+			return err
+		}
+		sums = append(sums, sum)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, sum := range sums {
+		if dryRun {
+			report.RefCountsFixed++
+			continue
+		}
+		fixed, err := fixBlobRefCount(ctx, sum)
+		if err != nil {
+			return err
+		}
+		if fixed {
+			report.RefCountsFixed++
+		}
+	}
+
+	rows, err = config.DB.Query(ctx, `SELECT sha256 FROM blobs WHERE ref_count = 0`)
+	if err != nil {
+		return err
+	}
+	sums = sums[:0]
+	for rows.Next() {
+		var sum string
+		if err := rows.Scan(&sum); err != nil {
+			rows.Close()
+			return err
+		}
+		sums = append(sums, sum)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, sum := range sums {
+		if !dryRun {
+			if err := releaseBlob(ctx, sum); err != nil {
+				slog.WarnContext(ctx, "Gagal menghapus blob", "sha256", sum, "error", err)
+				continue
+			}
+		}
+		report.BlobsReleased++
+	}
+	return nil
+}
+
+// fixBlobRefCount menghitung ulang ref_count blob sum
+func fixBlobRefCount(ctx context.Context, sum string) (bool, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var refs int
+	err = tx.QueryRow(ctx, `SELECT ref_count FROM blobs WHERE sha256 = $1 FOR UPDATE`, sum).Scan(&refs)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := tx.Exec(ctx,
+		`UPDATE blobs SET ref_count = (SELECT COUNT(*) FROM document_versions WHERE sha256 = $1)
+		 WHERE sha256 = $1 AND ref_count <> (SELECT COUNT(*) FROM document_versions WHERE sha256 = $1)`,
+		sum)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		slog.WarnContext(ctx, "ref_count blob dibetulkan", "sha256", sum, "previous", refs)
+	}
+	return tag.RowsAffected() > 0, tx.Commit(ctx)
+}
+
+// loadGCRefs membaca semua file, dokumen, versi dan sesi upload dari database
+func loadGCRefs(ctx context.Context) (gcRefs, error) {
+	refs := gcRefs{
+		documents: map[string]bool{},
+		versions:  map[string]bool{},
+		sessions:  map[string]bool{},
+		files:     map[string]bool{},
+		blobs:     map[string]bool{},
+	}
+
+	rows, err := config.DB.Query(ctx,
+		`SELECT d.id, d.file_path, v.id, COALESCE(v.version_number, 0), COALESCE(v.file_path, '')
+		 FROM documents d LEFT JOIN document_versions v ON v.document_id = d.id`)
+	if err != nil {
+		return refs, err
+	}
+	addFile := func(id string, number int, filePath string) {
+		key := fileKey(filePath)
+		if key == "" || refs.files[key] {
+			return
+		}
+		refs.files[key] = true
+		refs.referenced = append(refs.referenced, MissingFile{DocumentID: id, VersionNumber: number, Key: key})
+	}
+	for rows.Next() {
+		var id, docPath, versionPath string
+		var versionID *string
+		var number int
+		if err := rows.Scan(&id, &docPath, &versionID, &number, &versionPath); err != nil {
+			rows.Close()
+			return refs, err
+		}
+		refs.documents[id] = true
+		if versionID != nil {
+			refs.versions[*versionID] = true
+			addFile(id, number, versionPath)
+		}
+		addFile(id, 0, docPath)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return refs, err
+	}
+
+	// Blob yang masih tercatat tidak dihapus sebagai file yatim, blob tanpa
+	// referensi sudah ditangani fixBlobRefCounts
+	if err := collectKeys(ctx, refs.blobs, `SELECT file_path FROM blobs`); err != nil {
+		return refs, err
+	}
+	if err := collectKeys(ctx, refs.sessions, `SELECT id::text FROM upload_sessions`); err != nil {
+		return refs, err
+	}
+	return refs, nil
+}
+
+// collectKeys mengisi set dengan kolom pertama hasil query
+func collectKeys(ctx context.Context, set map[string]bool, query string) error {
+	rows, err := config.DB.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+		set[key] = true
+	}
+	return rows.Err()
+}
+
+// isOrphan menentukan apakah file dengan key tidak dirujuk database.
+// Folder di luar yang dikenal di bawah ini tidak pernah dianggap yatim.
+func (refs gcRefs) isOrphan(key string) bool {
+	if refs.files[key] || refs.blobs[key] {
+		return false
+	}
+
+	parts := strings.Split(key, "/")
+	switch {
+	case len(parts) == 1:
+		// File dokumen lama di root storage
+		return true
+	case parts[0] == "blobs" || parts[0] == stagingDir:
+		return true
+	case parts[0] == "chunks":
+		return !refs.sessions[parts[1]]
+	case parts[0] == "split":
+		if !refs.documents[parts[1]] {
+			return true
+		}
+		// split/<document_id>/<version_id>/..., halaman hasil split dokumen
+		// lama langsung berada di split/<document_id>/
+		if len(parts) > 3 {
+			if _, err := uuid.Parse(parts[2]); err == nil {
+				return !refs.versions[parts[2]]
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"repository-un/internal/config"
+)
+
+// readyTimeout membatasi waktu setiap pengecekan /readyz
+const readyTimeout = 3 * time.Second
+
+// HealthzHandler menjawab 200 selama proses server hidup, tanpa mengecek
+// dependensi. Dipakai sebagai liveness probe.
+// GET /healthz
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// ReadyzHandler mengecek database, storage, scanner dan antrian job. Jika salah
+// satu gagal dijawab 503 agar load balancer berhenti mengirim request.
+// Detail error hanya ditulis ke log.
+// GET /readyz
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+	}{
+		{"database", config.DB.Ping},
+		{"storage", config.Storage.Check},
+		{"scanner", config.Scanner.Check},
+		{"jobs", func(ctx context.Context) error {
+			if config.Jobs == nil {
+				return errors.New("worker job belum dijalankan")
+			}
+			return config.Jobs.Check(ctx)
+		}},
+	}
+
+	status, code := "ok", http.StatusOK
+	results := map[string]string{}
+	for _, c := range checks {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		err := c.check(ctx)
+		cancel()
+
+		if err != nil {
+			slog.WarnContext(r.Context(), "Pengecekan readiness gagal", "check", c.name, "error", err)
+			results[c.name] = "fail"
+			status, code = "unavailable", http.StatusServiceUnavailable
+			continue
+		}
+		results[c.name] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": results,
+	})
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Batas panjang dan jumlah metadata dokumen
+const (
+	maxTitleLength    = 500
+	maxAbstractLength = 10000
+	maxNameLength     = 255
+	maxKeywordLength  = 100
+	maxAuthors        = 20
+	maxAdvisors       = 5
+	maxKeywords       = 30
+	maxIdentifiers    = 10
+)
+
+// identifierPatterns adalah skema identifier yang diterima beserta format nilainya.
+// Nilai nil berarti format bebas.
+var identifierPatterns = map[string]*regexp.Regexp{
+	"doi":    regexp.MustCompile(`^10\.\d{4,9}/\S+$`),
+	"isbn":   regexp.MustCompile(`^(\d{9}[\dX]|\d{13})$`),
+	"issn":   regexp.MustCompile(`^\d{4}-\d{3}[\dX]$`),
+	"handle": regexp.MustCompile(`^[^/\s]+/\S+$`),
+	"url":    nil,
+	"nim":    regexp.MustCompile(`^[A-Za-z0-9.\-]+$`),
+	"other":  nil,
+}
+
+// languagePattern menerima kode bahasa ISO 639 / BCP 47, misalnya "id" atau "en-US"
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// parseDocumentRequest membaca metadata dokumen dari request. Metadata bisa
+// dikirim sebagai body JSON, sebagai JSON di field form "metadata", atau
+// lewat field form lama title/author/category.
+func parseDocumentRequest(r *http.Request) (models.CreateDocumentRequest, error) {
+	var req models.CreateDocumentRequest
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, apierror.BadRequest
+		}
+		return req, nil
+	}
+
+	if data := r.FormValue("metadata"); data != "" {
+		if err := json.Unmarshal([]byte(data), &req); err != nil {
+			return req, errInvalidMetadata.WithFields(apierror.Field("metadata", "invalid_json",
+				"bukan JSON yang valid", "is not valid JSON"))
+		}
+		return req, nil
+	}
+
+	req.Title = r.FormValue("title")
+	req.Author = r.FormValue("author")
+	req.Category = r.FormValue("category")
+	return req, nil
+}
+
+// normalizeMetadata merapikan metadata (trim spasi, buang nilai kosong dan
+// kata kunci ganda) lalu memvalidasinya
+func normalizeMetadata(req *models.CreateDocumentRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Category = strings.TrimSpace(req.Category)
+	req.Abstract = strings.TrimSpace(req.Abstract)
+	req.Faculty = strings.TrimSpace(req.Faculty)
+	req.Department = strings.TrimSpace(req.Department)
+	req.Publisher = strings.TrimSpace(req.Publisher)
+	req.Language = strings.TrimSpace(req.Language)
+
+	if len(req.Authors) == 0 && strings.TrimSpace(req.Author) != "" {
+		req.Authors = []string{req.Author}
+	}
+	req.Author = ""
+	req.Authors = compactStrings(req.Authors, false)
+	req.Advisors = compactStrings(req.Advisors, false)
+	req.Keywords = compactStrings(req.Keywords, true)
+
+	identifiers := []models.DocumentIdentifier{}
+	seen := map[models.DocumentIdentifier]bool{}
+	for _, id := range req.Identifiers {
+		id.Scheme = strings.ToLower(strings.TrimSpace(id.Scheme))
+		id.Value = strings.TrimSpace(id.Value)
+		if id.Scheme == "isbn" {
+			id.Value = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(id.Value))
+		}
+		if id.Scheme == "issn" {
+			id.Value = strings.ToUpper(id.Value)
+		}
+		if id.Value == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		identifiers = append(identifiers, id)
+	}
+	req.Identifiers = identifiers
+
+	return validateMetadata(req)
+}
+
+// validateMetadata memeriksa metadata yang sudah dinormalisasi
+func validateMetadata(req *models.CreateDocumentRequest) error {
+	var errs []apierror.FieldError
+	add := func(field, code, id, en string, args ...interface{}) {
+		errs = append(errs, apierror.Field(field, code, id, en, args...))
+	}
+	tooLong := func(field string, max int) {
+		add(field, "too_long", "maksimal %d karakter", "must be at most %d characters", max)
+	}
+
+	switch {
+	case req.Title == "":
+		errs = append(errs, apierror.Required("title"))
+	case utf8.RuneCountInString(req.Title) > maxTitleLength:
+		tooLong("title", maxTitleLength)
+	}
+
+	switch {
+	case req.Category == "":
+		errs = append(errs, apierror.Required("category"))
+	case utf8.RuneCountInString(req.Category) > maxNameLength:
+		tooLong("category", maxNameLength)
+	}
+
+	switch {
+	case len(req.Authors) == 0:
+		add("authors", "required", "minimal satu penulis", "at least one author is required")
+	case len(req.Authors) > maxAuthors:
+		add("authors", "too_many", "maksimal %d penulis", "at most %d authors", maxAuthors)
+	}
+	for i, name := range req.Authors {
+		if utf8.RuneCountInString(name) > maxNameLength {
+			tooLong(fmt.Sprintf("authors[%d]", i), maxNameLength)
+		}
+	}
+
+	if len(req.Advisors) > maxAdvisors {
+		add("advisors", "too_many", "maksimal %d pembimbing", "at most %d advisors", maxAdvisors)
+	}
+	for i, name := range req.Advisors {
+		if utf8.RuneCountInString(name) > maxNameLength {
+			tooLong(fmt.Sprintf("advisors[%d]", i), maxNameLength)
+		}
+	}
+
+	if utf8.RuneCountInString(req.Abstract) > maxAbstractLength {
+		tooLong("abstract", maxAbstractLength)
+	}
+
+	if len(req.Keywords) > maxKeywords {
+		add("keywords", "too_many", "maksimal %d kata kunci", "at most %d keywords", maxKeywords)
+	}
+	for i, kw := range req.Keywords {
+		if utf8.RuneCountInString(kw) > maxKeywordLength {
+			tooLong(fmt.Sprintf("keywords[%d]", i), maxKeywordLength)
+		}
+	}
+
+	if req.Year != nil {
+		maxYear := time.Now().Year() + 1
+		if *req.Year < 1900 || *req.Year > maxYear {
+			add("year", "out_of_range", "harus antara 1900 dan %d", "must be between 1900 and %d", maxYear)
+		}
+	}
+
+	for _, f := range []struct{ field, value string }{
+		{"faculty", req.Faculty},
+		{"department", req.Department},
+		{"publisher", req.Publisher},
+	} {
+		if utf8.RuneCountInString(f.value) > maxNameLength {
+			tooLong(f.field, maxNameLength)
+		}
+	}
+
+	if req.Language != "" && !languagePattern.MatchString(req.Language) {
+		add("language", "invalid", "harus kode bahasa ISO 639, misalnya id atau en", "must be an ISO 639 language code, e.g. id or en")
+	}
+
+	if len(req.Identifiers) > maxIdentifiers {
+		add("identifiers", "too_many", "maksimal %d identifier", "at most %d identifiers", maxIdentifiers)
+	}
+	for i, id := range req.Identifiers {
+		field := fmt.Sprintf("identifiers[%d]", i)
+		pattern, ok := identifierPatterns[id.Scheme]
+		switch {
+		case !ok:
+			add(field, "unknown_scheme", "skema %q tidak dikenal (doi, isbn, issn, handle, url, nim, other)",
+				"unknown scheme %q (doi, isbn, issn, handle, url, nim, other)", id.Scheme)
+		case utf8.RuneCountInString(id.Value) > maxNameLength:
+			tooLong(field, maxNameLength)
+		case id.Scheme == "url" && !isHTTPURL(id.Value):
+			add(field, "invalid", "URL harus diawali http:// atau https://", "URL must start with http:// or https://")
+		case pattern != nil && !pattern.MatchString(id.Value):
+			add(field, "invalid", "format %s tidak valid", "invalid %s format", id.Scheme)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errInvalidMetadata.WithFields(errs...)
+	}
+	return nil
+}
+
+// saveMetadata menyimpan seluruh metadata dokumen di dalam transaksi tx.
+// Penulis, pembimbing, kata kunci dan identifier lama diganti.
+func saveMetadata(ctx context.Context, tx pgx.Tx, id string, req models.CreateDocumentRequest) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE documents
+		 SET judul = $1, penulis = $2, jenis_file = $3, abstract = $4, year = $5,
+		     faculty = $6, department = $7, language = $8, publisher = $9,
+		     updated_at = NOW()
+		 WHERE id = $10`,
+		req.Title, strings.Join(req.Authors, ", "), req.Category, req.Abstract, req.Year,
+		req.Faculty, req.Department, req.Language, req.Publisher, id)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"document_authors", "document_keywords", "document_identifiers"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE document_id = $1`, id); err != nil {
+			return err
+		}
+	}
+
+	for role, names := range map[string][]string{"author": req.Authors, "advisor": req.Advisors} {
+		for i, name := range names {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO document_authors (document_id, role, position, name) VALUES ($1, $2, $3, $4)`,
+				id, role, i+1, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, kw := range req.Keywords {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO document_keywords (document_id, position, keyword) VALUES ($1, $2, $3)`,
+			id, i+1, kw)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ident := range req.Identifiers {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO document_identifiers (document_id, scheme, value) VALUES ($1, $2, $3)`,
+			id, ident.Scheme, ident.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadDocument mengambil dokumen beserta seluruh metadatanya
+func loadDocument(ctx context.Context, id string) (models.Document, error) {
+	var d models.Document
+	err := config.DB.QueryRow(ctx,
+		`SELECT id, judul, penulis, jenis_file, mime_type, status, owner_id,
+		        abstract, year, faculty, department, language, publisher,
+		        processing_status, processing_error, created_at, updated_at
+		 FROM documents WHERE id = $1`, id).Scan(
+		&d.ID,
+		&d.Judul,
+		&d.Penulis,
+		&d.JenisFile,
+		&d.MimeType,
+		&d.Status,
+		&d.OwnerID,
+		&d.Abstract,
+		&d.Year,
+		&d.Faculty,
+		&d.Department,
+		&d.Language,
+		&d.Publisher,
+		&d.ProcessingStatus,
+		&d.ProcessingError,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return d, err
+	}
+
+	d.Authors, d.Advisors = []string{}, []string{}
+	rows, err := config.DB.Query(ctx,
+		`SELECT role, name FROM document_authors WHERE document_id = $1 ORDER BY role, position`, id)
+	if err != nil {
+		return d, err
+	}
+	for rows.Next() {
+		var role, name string
+		if err := rows.Scan(&role, &name); err != nil {
+			rows.Close()
+			return d, err
+		}
+		if role == "advisor" {
+			d.Advisors = append(d.Advisors, name)
+		} else {
+			d.Authors = append(d.Authors, name)
+		}
+	}
+	rows.Close()
+
+	d.Keywords = []string{}
+	rows, err = config.DB.Query(ctx,
+		`SELECT keyword FROM document_keywords WHERE document_id = $1 ORDER BY position`, id)
+	if err != nil {
+		return d, err
+	}
+	for rows.Next() {
+		var kw string
+		if err := rows.Scan(&kw); err != nil {
+			rows.Close()
+			return d, err
+		}
+		d.Keywords = append(d.Keywords, kw)
+	}
+	rows.Close()
+
+	d.Identifiers = []models.DocumentIdentifier{}
+	rows, err = config.DB.Query(ctx,
+		`SELECT scheme, value FROM document_identifiers WHERE document_id = $1 ORDER BY scheme, value`, id)
+	if err != nil {
+		return d, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var ident models.DocumentIdentifier
+		if err := rows.Scan(&ident.Scheme, &ident.Value); err != nil {
+			return d, err
+		}
+		d.Identifiers = append(d.Identifiers, ident)
+	}
+
+	return d, rows.Err()
+}
+
+// compactStrings men-trim setiap nilai dan membuang yang kosong.
+// Jika unique, nilai ganda (tanpa membedakan huruf besar/kecil) juga dibuang.
+func compactStrings(values []string, unique bool) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if unique {
+			key := strings.ToLower(v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// isHTTPURL mengecek apakah v adalah URL http/https yang lengkap
+func isHTTPURL(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,720 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+	"repository-un/internal/workflow"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// oaiPageSize adalah jumlah record per response ListIdentifiers/ListRecords
+const oaiPageSize = 100
+
+// Format datestamp OAI-PMH (granularitas detik dan hari)
+const (
+	oaiTimeFormat = "2006-01-02T15:04:05Z"
+	oaiDayFormat  = "2006-01-02"
+)
+
+// oaiArguments adalah argumen yang boleh dipakai oleh setiap verb.
+// true berarti wajib (kecuali jika memakai resumptionToken).
+var oaiArguments = map[string]map[string]bool{
+	"Identify":            {},
+	"ListMetadataFormats": {"identifier": false},
+	"ListSets":            {"resumptionToken": false},
+	"GetRecord":           {"identifier": true, "metadataPrefix": true},
+	"ListIdentifiers":     {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+	"ListRecords":         {"metadataPrefix": true, "from": false, "until": false, "set": false, "resumptionToken": false},
+}
+
+// setSpecPattern membatasi karakter setSpec yang bisa dibuat dari jenis_file
+var setSpecPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// oaiError adalah kesalahan OAI-PMH dengan kode dari spesifikasi
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+
+	// server menandai kegagalan di sisi server (misalnya database) yang
+	// dikirim sebagai HTTP 500, bukan error OAI-PMH
+	server bool
+}
+
+func (e *oaiError) Error() string { return e.Code + ": " + e.Message }
+
+// oaiServerError mencatat kegagalan server ke log. Harvester akan mencoba
+// lagi request yang gagal, berbeda dengan badArgument yang berarti
+// request-nya salah.
+func oaiServerError(ctx context.Context, msg string, err error) *oaiError {
+	slog.ErrorContext(ctx, msg, "error", err)
+	return &oaiError{Message: msg, server: true}
+}
+
+func badArgument(format string, args ...interface{}) *oaiError {
+	return &oaiError{Code: "badArgument", Message: fmt.Sprintf(format, args...)}
+}
+
+// oaiRequest adalah elemen <request> yang mengulang argumen request
+type oaiRequest struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+// oaiResponse adalah root element OAI-PMH
+type oaiResponse struct {
+	XMLName        xml.Name    `xml:"OAI-PMH"`
+	Xmlns          string      `xml:"xmlns,attr"`
+	XmlnsXsi       string      `xml:"xmlns:xsi,attr"`
+	SchemaLocation string      `xml:"xsi:schemaLocation,attr"`
+	ResponseDate   string      `xml:"responseDate"`
+	Request        oaiRequest  `xml:"request"`
+	Errors         []*oaiError `xml:"error,omitempty"`
+
+	Identify            *oaiIdentify        `xml:"Identify,omitempty"`
+	ListMetadataFormats *oaiMetadataFormats `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *oaiSets            `xml:"ListSets,omitempty"`
+	GetRecord           *oaiRecords         `xml:"GetRecord,omitempty"`
+	ListIdentifiers     *oaiHeaders         `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *oaiRecords         `xml:"ListRecords,omitempty"`
+}
+
+type oaiIdentify struct {
+	RepositoryName    string `xml:"repositoryName"`
+	BaseURL           string `xml:"baseURL"`
+	ProtocolVersion   string `xml:"protocolVersion"`
+	AdminEmail        string `xml:"adminEmail"`
+	EarliestDatestamp string `xml:"earliestDatestamp"`
+	DeletedRecord     string `xml:"deletedRecord"`
+	Granularity       string `xml:"granularity"`
+}
+
+type oaiMetadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type oaiMetadataFormats struct {
+	Formats []oaiMetadataFormat `xml:"metadataFormat"`
+}
+
+type oaiSet struct {
+	SetSpec string `xml:"setSpec"`
+	SetName string `xml:"setName"`
+}
+
+type oaiSets struct {
+	Sets []oaiSet `xml:"set"`
+}
+
+type oaiHeader struct {
+	Status     string   `xml:"status,attr,omitempty"`
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpecs   []string `xml:"setSpec"`
+}
+
+type oaiRecord struct {
+	Header   oaiHeader    `xml:"header"`
+	Metadata *oaiMetadata `xml:"metadata,omitempty"`
+}
+
+type oaiMetadata struct {
+	DC oaiDC `xml:"oai_dc:dc"`
+}
+
+// oaiDC adalah record Dublin Core sederhana (oai_dc)
+type oaiDC struct {
+	XmlnsOaiDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXsi       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          []string `xml:"dc:title"`
+	Creator        []string `xml:"dc:creator"`
+	Contributor    []string `xml:"dc:contributor"`
+	Subject        []string `xml:"dc:subject"`
+	Description    []string `xml:"dc:description"`
+	Publisher      []string `xml:"dc:publisher"`
+	Date           []string `xml:"dc:date"`
+	Type           []string `xml:"dc:type"`
+	Format         []string `xml:"dc:format"`
+	Identifier     []string `xml:"dc:identifier"`
+	Language       []string `xml:"dc:language"`
+}
+
+type oaiToken struct {
+	Value            string `xml:",chardata"`
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+}
+
+type oaiHeaders struct {
+	Headers []oaiHeader `xml:"header"`
+	Token   *oaiToken   `xml:"resumptionToken,omitempty"`
+}
+
+type oaiRecords struct {
+	Records []oaiRecord `xml:"record"`
+	Token   *oaiToken   `xml:"resumptionToken,omitempty"`
+}
+
+// oaiDCFormat adalah satu-satunya metadata format yang didukung
+var oaiDCFormat = oaiMetadataFormat{
+	MetadataPrefix:    "oai_dc",
+	Schema:            "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+	MetadataNamespace: "http://www.openarchives.org/OAI/2.0/oai_dc/",
+}
+
+// harvestQuery adalah parameter selective harvesting. Ikut disimpan di dalam
+// resumptionToken bersama posisi record terakhir.
+type harvestQuery struct {
+	Prefix string     `json:"p"`
+	Set    string     `json:"s,omitempty"`
+	From   *time.Time `json:"f,omitempty"`
+	Until  *time.Time `json:"u,omitempty"`
+
+	// Posisi terakhir (keyset updated_at, id) dan jumlah record yang sudah dikirim
+	AfterTime *time.Time `json:"t,omitempty"`
+	AfterID   string     `json:"i,omitempty"`
+	Cursor    int        `json:"c"`
+}
+
+// OAIHandler menangani endpoint OAI-PMH 2.0 untuk harvesting repository
+// GET/POST /oai?verb=Identify|ListMetadataFormats|ListSets|ListIdentifiers|ListRecords|GetRecord
+//
+// Hanya dokumen published yang bisa di-harvest. Dokumen yang pernah
+// published lalu ditarik (misalnya diarsipkan) dilaporkan sebagai deleted.
+func OAIHandler(w http.ResponseWriter, r *http.Request) {
+	resp := &oaiResponse{
+		Xmlns:          "http://www.openarchives.org/OAI/2.0/",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd",
+		ResponseDate:   time.Now().UTC().Format(oaiTimeFormat),
+		Request:        oaiRequest{URL: oaiBaseURL(r)},
+	}
+
+	if err := r.ParseForm(); err != nil {
+		resp.Errors = append(resp.Errors, badArgument("request tidak valid"))
+		writeOAI(w, r, resp)
+		return
+	}
+
+	if err := handleOAIVerb(r, resp); err != nil {
+		if err.server {
+			apierror.Write(w, r, apierror.Internal)
+			return
+		}
+		resp.Errors = append(resp.Errors, err)
+	}
+	writeOAI(w, r, resp)
+}
+
+// handleOAIVerb memvalidasi argumen lalu menjalankan verb
+func handleOAIVerb(r *http.Request, resp *oaiResponse) *oaiError {
+	args := r.Form
+	verb := args.Get("verb")
+
+	allowed, ok := oaiArguments[verb]
+	if !ok || len(args["verb"]) != 1 {
+		return &oaiError{Code: "badVerb", Message: "verb tidak dikenal atau tidak diisi"}
+	}
+
+	for name, values := range args {
+		if name == "verb" {
+			continue
+		}
+		if _, ok := allowed[name]; !ok {
+			return badArgument("argumen %s tidak berlaku untuk %s", name, verb)
+		}
+		if len(values) != 1 {
+			return badArgument("argumen %s diisi lebih dari sekali", name)
+		}
+	}
+
+	// resumptionToken adalah argumen eksklusif
+	if args.Get("resumptionToken") != "" && len(args) > 2 {
+		return badArgument("resumptionToken tidak boleh digabung dengan argumen lain")
+	}
+	if args.Get("resumptionToken") == "" {
+		for name, required := range allowed {
+			if required && args.Get(name) == "" {
+				return badArgument("argumen %s wajib diisi", name)
+			}
+		}
+	}
+
+	// Argumen hanya diulang di <request> jika request valid
+	resp.Request.Verb = verb
+	resp.Request.Identifier = args.Get("identifier")
+	resp.Request.MetadataPrefix = args.Get("metadataPrefix")
+	resp.Request.From = args.Get("from")
+	resp.Request.Until = args.Get("until")
+	resp.Request.Set = args.Get("set")
+	resp.Request.ResumptionToken = args.Get("resumptionToken")
+
+	ctx := r.Context()
+	switch verb {
+	case "Identify":
+		return oaiIdentifyVerb(ctx, r, resp)
+	case "ListMetadataFormats":
+		return oaiListMetadataFormats(ctx, args.Get("identifier"), resp)
+	case "ListSets":
+		return oaiListSets(ctx, args.Get("resumptionToken"), resp)
+	case "GetRecord":
+		return oaiGetRecord(ctx, r, args.Get("identifier"), args.Get("metadataPrefix"), resp)
+	default:
+		q, err := parseHarvestQuery(args)
+		if err != nil {
+			return err
+		}
+		return oaiList(ctx, r, verb == "ListRecords", q, resp)
+	}
+}
+
+func oaiIdentifyVerb(ctx context.Context, r *http.Request, resp *oaiResponse) *oaiError {
+	earliest := time.Now().UTC()
+	var min *time.Time
+	err := config.DB.QueryRow(ctx,
+		`SELECT MIN(d.updated_at) FROM documents d WHERE `+oaiHarvestableCondition).Scan(&min)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return oaiServerError(ctx, "OAI-PMH: gagal mengambil earliestDatestamp", err)
+	}
+	if min != nil {
+		earliest = *min
+	}
+
+	resp.Identify = &oaiIdentify{
+		RepositoryName:    config.App.OAI.RepositoryName,
+		BaseURL:           oaiBaseURL(r),
+		ProtocolVersion:   "2.0",
+		AdminEmail:        config.App.OAI.AdminEmail,
+		EarliestDatestamp: earliest.UTC().Format(oaiTimeFormat),
+		DeletedRecord:     "transient",
+		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+	}
+	return nil
+}
+
+func oaiListMetadataFormats(ctx context.Context, identifier string, resp *oaiResponse) *oaiError {
+	if identifier != "" {
+		if _, err := loadOAIDocument(ctx, identifier); err != nil {
+			return err
+		}
+	}
+	resp.ListMetadataFormats = &oaiMetadataFormats{Formats: []oaiMetadataFormat{oaiDCFormat}}
+	return nil
+}
+
+// oaiListSets mengembalikan set per jenis dokumen (type:<jenis_file>)
+func oaiListSets(ctx context.Context, token string, resp *oaiResponse) *oaiError {
+	if token != "" {
+		// Daftar set selalu dikirim lengkap, tidak pernah memberi token
+		return &oaiError{Code: "badResumptionToken", Message: "resumptionToken tidak valid"}
+	}
+
+	rows, err := config.DB.Query(ctx,
+		`SELECT DISTINCT d.jenis_file FROM documents d WHERE `+oaiHarvestableCondition+` ORDER BY d.jenis_file`)
+	if err != nil {
+		return oaiServerError(ctx, "OAI-PMH: gagal mengambil set", err)
+	}
+	defer rows.Close()
+
+	sets := &oaiSets{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var jenisFile string
+		if err := rows.Scan(&jenisFile); err != nil {
+			return oaiServerError(ctx, "OAI-PMH: gagal membaca set", err)
+		}
+		spec := oaiSetSpec(jenisFile)
+		if spec == "" || seen[spec] {
+			continue
+		}
+		seen[spec] = true
+		sets.Sets = append(sets.Sets, oaiSet{SetSpec: spec, SetName: jenisFile})
+	}
+	if err := rows.Err(); err != nil {
+		return oaiServerError(ctx, "OAI-PMH: gagal membaca set", err)
+	}
+
+	if len(sets.Sets) == 0 {
+		return &oaiError{Code: "noSetHierarchy", Message: "repository belum memiliki set"}
+	}
+	resp.ListSets = sets
+	return nil
+}
+
+func oaiGetRecord(ctx context.Context, r *http.Request, identifier, prefix string, resp *oaiResponse) *oaiError {
+	if prefix != oaiDCFormat.MetadataPrefix {
+		return &oaiError{Code: "cannotDisseminateFormat", Message: "hanya mendukung oai_dc"}
+	}
+
+	doc, err := loadOAIDocument(ctx, identifier)
+	if err != nil {
+		return err
+	}
+
+	resp.GetRecord = &oaiRecords{Records: []oaiRecord{oaiRecordFor(r, doc, true)}}
+	return nil
+}
+
+// oaiList menjalankan ListIdentifiers atau ListRecords
+func oaiList(ctx context.Context, r *http.Request, withMetadata bool, q harvestQuery, resp *oaiResponse) *oaiError {
+	where, args := q.where()
+
+	var total int
+	err := config.DB.QueryRow(ctx, `SELECT COUNT(*) FROM documents d WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return oaiServerError(ctx, "OAI-PMH: gagal menghitung record", err)
+	}
+
+	// Keyset (updated_at, id) agar record baru tidak menggeser halaman berikutnya
+	pageWhere, pageArgs := where, args
+	if q.AfterTime != nil {
+		pageArgs = append(pageArgs, *q.AfterTime, q.AfterID)
+		pageWhere += fmt.Sprintf(" AND (d.updated_at, d.id) > ($%d, $%d)", len(pageArgs)-1, len(pageArgs))
+	}
+	pageArgs = append(pageArgs, oaiPageSize+1)
+
+	rows, err := config.DB.Query(ctx,
+		`SELECT d.id, d.file_path, d.updated_at FROM documents d WHERE `+pageWhere+
+			fmt.Sprintf(` ORDER BY d.updated_at, d.id LIMIT $%d`, len(pageArgs)),
+		pageArgs...)
+	if err != nil {
+		return oaiServerError(ctx, "OAI-PMH: gagal mengambil record", err)
+	}
+
+	type hit struct {
+		id        string
+		filePath  string
+		updatedAt time.Time
+	}
+	var hits []hit
+	for rows.Next() {
+		var h hit
+		if err := rows.Scan(&h.id, &h.filePath, &h.updatedAt); err != nil {
+			rows.Close()
+			return oaiServerError(ctx, "OAI-PMH: gagal membaca record", err)
+		}
+		hits = append(hits, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return oaiServerError(ctx, "OAI-PMH: gagal membaca record", err)
+	}
+
+	if len(hits) == 0 {
+		if q.AfterTime != nil {
+			return &oaiError{Code: "badResumptionToken", Message: "resumptionToken sudah tidak berlaku"}
+		}
+		return &oaiError{Code: "noRecordsMatch", Message: "tidak ada record yang cocok"}
+	}
+
+	// Token berikutnya hanya jika masih ada record setelah halaman ini.
+	// Halaman terakhir dari list yang memakai token mendapat token kosong.
+	var token *oaiToken
+	if len(hits) > oaiPageSize {
+		hits = hits[:oaiPageSize]
+		last := hits[len(hits)-1]
+		next := q
+		next.AfterTime, next.AfterID = &last.updatedAt, last.id
+		next.Cursor = q.Cursor + len(hits)
+		token = &oaiToken{Value: next.encode(), CompleteListSize: total, Cursor: q.Cursor}
+	} else if q.AfterTime != nil {
+		token = &oaiToken{CompleteListSize: total, Cursor: q.Cursor}
+	}
+
+	records := make([]oaiRecord, 0, len(hits))
+	for _, h := range hits {
+		doc, err := loadDocument(ctx, h.id)
+		if err != nil {
+			// Dokumen terhapus di antara dua query
+			continue
+		}
+		doc.FilePath = h.filePath
+		records = append(records, oaiRecordFor(r, doc, withMetadata))
+	}
+
+	if withMetadata {
+		resp.ListRecords = &oaiRecords{Records: records, Token: token}
+		return nil
+	}
+
+	headers := make([]oaiHeader, len(records))
+	for i, rec := range records {
+		headers[i] = rec.Header
+	}
+	resp.ListIdentifiers = &oaiHeaders{Headers: headers, Token: token}
+	return nil
+}
+
+// oaiHarvestableCondition membatasi dokumen yang terlihat lewat OAI-PMH:
+// published, atau pernah published (dilaporkan sebagai deleted)
+var oaiHarvestableCondition = fmt.Sprintf(
+	`(d.status = '%s' OR EXISTS (
+	    SELECT 1 FROM document_status_history h
+	    WHERE h.document_id = d.id AND h.to_status = '%s'))`,
+	workflow.StatusPublished, workflow.StatusPublished)
+
+// where menyusun kondisi selective harvesting (tanpa posisi token)
+func (q harvestQuery) where() (string, []interface{}) {
+	conditions := []string{oaiHarvestableCondition}
+	var args []interface{}
+
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if q.Set != "" {
+		add(`trim(both '-' from regexp_replace(lower(d.jenis_file), '[^a-z0-9]+', '-', 'g')) = $%d`,
+			strings.TrimPrefix(q.Set, "type:"))
+	}
+	if q.From != nil {
+		add("d.updated_at >= $%d", *q.From)
+	}
+	if q.Until != nil {
+		add("d.updated_at < $%d", *q.Until)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// encode membuat resumptionToken dari query
+func (q harvestQuery) encode() string {
+	data, _ := json.Marshal(q)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseHarvestQuery membaca argumen ListIdentifiers/ListRecords atau
+// resumptionToken
+func parseHarvestQuery(args url.Values) (harvestQuery, *oaiError) {
+	var q harvestQuery
+
+	if token := args.Get("resumptionToken"); token != "" {
+		data, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || json.Unmarshal(data, &q) != nil || q.AfterTime == nil {
+			return q, &oaiError{Code: "badResumptionToken", Message: "resumptionToken tidak valid"}
+		}
+		if _, err := uuid.Parse(q.AfterID); err != nil {
+			return q, &oaiError{Code: "badResumptionToken", Message: "resumptionToken tidak valid"}
+		}
+		return q, nil
+	}
+
+	q.Prefix = args.Get("metadataPrefix")
+	if q.Prefix != oaiDCFormat.MetadataPrefix {
+		return q, &oaiError{Code: "cannotDisseminateFormat", Message: "hanya mendukung oai_dc"}
+	}
+
+	if set := args.Get("set"); set != "" {
+		if !strings.HasPrefix(set, "type:") {
+			return q, &oaiError{Code: "noRecordsMatch", Message: "set tidak dikenal"}
+		}
+		q.Set = set
+	}
+
+	from, fromDay, err := parseOAIDate(args.Get("from"), false)
+	if err != nil {
+		return q, badArgument("from tidak valid")
+	}
+	until, untilDay, err := parseOAIDate(args.Get("until"), true)
+	if err != nil {
+		return q, badArgument("until tidak valid")
+	}
+	if from != nil && until != nil {
+		if fromDay != untilDay {
+			return q, badArgument("from dan until harus memakai granularitas yang sama")
+		}
+		if !from.Before(*until) {
+			return q, badArgument("from tidak boleh setelah until")
+		}
+	}
+	q.From, q.Until = from, until
+
+	return q, nil
+}
+
+// parseOAIDate membaca datestamp YYYY-MM-DD atau YYYY-MM-DDThh:mm:ssZ.
+// Untuk until, waktu dijadikan batas eksklusif (akhir hari / detik berikutnya).
+func parseOAIDate(v string, until bool) (*time.Time, bool, error) {
+	if v == "" {
+		return nil, false, nil
+	}
+
+	if t, err := time.Parse(oaiDayFormat, v); err == nil {
+		if until {
+			t = t.AddDate(0, 0, 1)
+		}
+		return &t, true, nil
+	}
+
+	t, err := time.Parse(oaiTimeFormat, v)
+	if err != nil {
+		return nil, false, err
+	}
+	if until {
+		t = t.Add(time.Second)
+	}
+	return &t, false, nil
+}
+
+// loadOAIDocument mengambil dokumen dari identifier oai:<repo>:<id>
+func loadOAIDocument(ctx context.Context, identifier string) (models.Document, *oaiError) {
+	notFound := &oaiError{Code: "idDoesNotExist", Message: "identifier tidak ditemukan"}
+
+	prefix := "oai:" + config.App.OAI.RepositoryIdentifier + ":"
+	id := strings.TrimPrefix(identifier, prefix)
+	if id == identifier {
+		return models.Document{}, notFound
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return models.Document{}, notFound
+	}
+
+	var harvestable bool
+	var filePath string
+	err := config.DB.QueryRow(ctx,
+		`SELECT `+oaiHarvestableCondition+`, d.file_path FROM documents d WHERE d.id = $1`,
+		id).Scan(&harvestable, &filePath)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !harvestable) {
+		return models.Document{}, notFound
+	}
+	if err != nil {
+		return models.Document{}, oaiServerError(ctx, "OAI-PMH: gagal mengambil dokumen", err)
+	}
+
+	doc, err := loadDocument(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Document{}, notFound
+	}
+	if err != nil {
+		return models.Document{}, oaiServerError(ctx, "OAI-PMH: gagal mengambil dokumen", err)
+	}
+	doc.FilePath = filePath
+	return doc, nil
+}
+
+// oaiRecordFor mengubah dokumen menjadi record OAI-PMH. Dokumen yang tidak
+// lagi published hanya berisi header dengan status deleted.
+func oaiRecordFor(r *http.Request, d models.Document, withMetadata bool) oaiRecord {
+	rec := oaiRecord{
+		Header: oaiHeader{
+			Identifier: "oai:" + config.App.OAI.RepositoryIdentifier + ":" + d.ID,
+			Datestamp:  d.UpdatedAt.UTC().Format(oaiTimeFormat),
+		},
+	}
+	if spec := oaiSetSpec(d.JenisFile); spec != "" {
+		rec.Header.SetSpecs = []string{spec}
+	}
+
+	if d.Status != workflow.StatusPublished {
+		rec.Header.Status = "deleted"
+		return rec
+	}
+	if !withMetadata {
+		return rec
+	}
+
+	dc := oaiDC{
+		XmlnsOaiDC:     "http://www.openarchives.org/OAI/2.0/oai_dc/",
+		XmlnsDC:        "http://purl.org/dc/elements/1.1/",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		Title:          []string{d.Judul},
+		Creator:        d.Authors,
+		Contributor:    d.Advisors,
+		Subject:        d.Keywords,
+		Type:           []string{d.JenisFile},
+		Identifier:     []string{oaiServerURL(r) + "/download/" + d.ID},
+	}
+	if d.Abstract != "" {
+		dc.Description = []string{d.Abstract}
+	}
+	if d.Publisher != "" {
+		dc.Publisher = []string{d.Publisher}
+	}
+	if d.Year != nil {
+		dc.Date = []string{strconv.Itoa(*d.Year)}
+	}
+	if d.Language != "" {
+		dc.Language = []string{d.Language}
+	}
+	if strings.EqualFold(path.Ext(d.FilePath), ".pdf") {
+		dc.Format = []string{"application/pdf"}
+	}
+	for _, ident := range d.Identifiers {
+		switch ident.Scheme {
+		case "url", "other":
+			dc.Identifier = append(dc.Identifier, ident.Value)
+		default:
+			dc.Identifier = append(dc.Identifier, ident.Scheme+":"+ident.Value)
+		}
+	}
+
+	rec.Metadata = &oaiMetadata{DC: dc}
+	return rec
+}
+
+// oaiSetSpec membuat setSpec dari jenis_file, misalnya "Skripsi S1" -> "type:skripsi-s1"
+func oaiSetSpec(jenisFile string) string {
+	slug := strings.Trim(setSpecPattern.ReplaceAllString(strings.ToLower(jenisFile), "-"), "-")
+	if slug == "" {
+		return ""
+	}
+	return "type:" + slug
+}
+
+// oaiBaseURL mengembalikan URL publik endpoint /oai
+func oaiBaseURL(r *http.Request) string {
+	return oaiServerURL(r) + "/oai"
+}
+
+// oaiServerURL mengembalikan URL publik server, dari OAI_BASE_URL atau request
+func oaiServerURL(r *http.Request) string {
+	if config.App.OAI.BaseURL != "" {
+		return strings.TrimSuffix(config.App.OAI.BaseURL, "/oai")
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// writeOAI mengirim response OAI-PMH sebagai XML
+func writeOAI(w http.ResponseWriter, r *http.Request, resp *oaiResponse) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(resp); err != nil {
+		slog.WarnContext(r.Context(), "Gagal menulis response OAI-PMH", "error", err)
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/jobs"
+	"repository-un/internal/metrics"
+	"repository-un/internal/models"
+	"repository-un/internal/storage"
+	"repository-un/internal/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// jobProcessDocument adalah tipe job pemrosesan file dokumen
+// (validasi PDF, split per halaman, render gambar halaman dan index teks)
+const jobProcessDocument = "process_document"
+
+// Status pemrosesan file versi aktif dokumen
+const (
+	ProcessingPending    = "pending"
+	ProcessingProcessing = "processing"
+	ProcessingReady      = "ready"
+	ProcessingFailed     = "failed"
+
+	// ProcessingQuarantined berarti file aktif terdeteksi malware atau
+	// tidak dapat dipindai, lihat listQuarantine
+	ProcessingQuarantined = "quarantined"
+)
+
+// processDocumentPayload adalah payload job process_document
+type processDocumentPayload struct {
+	DocumentID string `json:"document_id"`
+	VersionID  string `json:"version_id"`
+}
+
+// RegisterJobs mendaftarkan handler job milik package handlers.
+// Dipanggil sebelum config.StartJobWorker.
+func RegisterJobs() {
+	jobs.Register(jobProcessDocument, processDocumentJob)
+	jobs.Register(jobExpireUpload, expireUploadJob)
+}
+
+// scheduleProcessing menandai dokumen pending dan mengantrikan pemrosesan
+// file versi versionID
+func scheduleProcessing(ctx context.Context, id, versionID string) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := enqueueProcessing(ctx, tx, id, versionID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// enqueueProcessing adalah scheduleProcessing di dalam transaksi tx yang
+// sudah berjalan. Job baru dijalankan worker setelah tx di-commit.
+func enqueueProcessing(ctx context.Context, tx pgx.Tx, id, versionID string) error {
+	_, err := tx.Exec(ctx,
+		`UPDATE documents SET processing_status = $1, processing_error = NULL WHERE id = $2`,
+		ProcessingPending, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = jobs.Enqueue(ctx, tx, jobProcessDocument, processDocumentPayload{
+		DocumentID: id,
+		VersionID:  versionID,
+	})
+	return err
+}
+
+// reprocessDocument menjalankan ulang pemrosesan file versi aktif,
+// misalnya setelah split gagal (hanya pemilik atau admin)
+// POST /api/documents/:id/reprocess
+func reprocessDocument(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := authorizeEdit(w, r, id); !ok {
+		return
+	}
+
+	var versionID *string
+	var status string
+	err := config.DB.QueryRow(r.Context(),
+		`SELECT current_version_id, processing_status FROM documents WHERE id = $1`, id).Scan(&versionID, &status)
+	if err != nil || versionID == nil {
+		apierror.Write(w, r, errDocumentNotFound)
+		return
+	}
+
+	if status == ProcessingPending || status == ProcessingProcessing {
+		apierror.Write(w, r, errDocumentProcessing)
+		return
+	}
+
+	if err := scheduleProcessing(r.Context(), id, *versionID); err != nil {
+		apierror.Write(w, r, errScheduleFailed)
+		return
+	}
+
+	recordAudit(r, auditEvent{Action: auditReprocess, TargetType: targetDocument, TargetID: id})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                id,
+		"processing_status": ProcessingPending,
+	})
+}
+
+// processDocumentJob adalah handler job process_document
+func processDocumentJob(ctx context.Context, job jobs.Job) error {
+	var p processDocumentPayload
+	if err := job.Decode(&p); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	var v models.DocumentVersion
+	err := config.DB.QueryRow(ctx,
+		`SELECT id, document_id, file_path, split_dir FROM document_versions WHERE id = $1`,
+		p.VersionID).Scan(&v.ID, &v.DocumentID, &v.FilePath, &v.SplitDir)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Dokumen sudah dihapus, tidak ada yang perlu diproses
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	setProcessingStatus(ctx, v, ProcessingProcessing, "")
+
+	// File dipindai dulu sebelum dibuka oleh pdfcpu/pdftoppm dan bisa didownload
+	start := time.Now()
+	err = scanVersionFile(ctx, v, job.LastAttempt())
+	if err == nil {
+		err = processVersion(ctx, &v)
+	}
+
+	var quarantined *quarantineError
+	result := "ready"
+	switch {
+	case err == nil:
+		setProcessingStatus(ctx, v, ProcessingReady, "")
+	case errors.As(err, &quarantined):
+		// File yang dikarantina tidak perlu dicoba ulang
+		result = "quarantined"
+		setProcessingStatus(ctx, v, ProcessingQuarantined, quarantined.reason)
+		err = nil
+	case jobs.IsPermanent(err) || job.LastAttempt():
+		result = "failed"
+		setProcessingStatus(ctx, v, ProcessingFailed, err.Error())
+	default:
+		// Masih akan dicoba ulang oleh worker
+		result = "retry"
+		setProcessingStatus(ctx, v, ProcessingPending, err.Error())
+	}
+
+	metrics.ProcessingDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ProcessingFailures.WithLabelValues(result).Inc()
+	}
+	return err
+}
+
+// processVersion memvalidasi, memecah per halaman, merender gambar halaman
+// dan mengindex teks file PDF dari versi dokumen. File selain PDF tidak perlu diproses.
+func processVersion(ctx context.Context, v *models.DocumentVersion) error {
+	key := fileKey(v.FilePath)
+	if strings.ToLower(path.Ext(key)) != ".pdf" {
+		return nil
+	}
+
+	if err := utils.ValidatePDF(ctx, config.Storage, key); err != nil {
+		if errors.Is(err, storage.ErrNotExist) {
+			return jobs.Permanent(fmt.Errorf("file tidak ditemukan: %w", err))
+		}
+		return jobs.Permanent(fmt.Errorf("file PDF rusak atau tidak valid: %w", err))
+	}
+
+	// Split PDF per halaman untuk preview. Halaman yang sudah ada ditimpa
+	// sehingga aman dijalankan ulang.
+	pageCount, err := utils.SplitPDF(ctx, config.Storage, key, v.SplitDir)
+	if err != nil {
+		return fmt.Errorf("gagal memecah PDF: %w", err)
+	}
+	v.PageCount = pageCount
+
+	_, err = config.DB.Exec(ctx,
+		`UPDATE document_versions SET page_count = $1 WHERE id = $2`, v.PageCount, v.ID)
+	if err != nil {
+		return err
+	}
+
+	// Render gambar halaman (medium dan thumbnail) untuk grid preview.
+	// Tanpa pdftoppm preview gambar dilewati, preview PDF tetap tersedia.
+	_, err = utils.RenderPDFPages(ctx, config.Storage, key, v.SplitDir, config.App.Preview.RenderOptions())
+	if errors.Is(err, utils.ErrRendererNotFound) {
+		slog.WarnContext(ctx, "Render gambar halaman dilewati", "document_id", v.DocumentID, "error", err)
+	} else if err != nil {
+		return fmt.Errorf("gagal merender gambar halaman: %w", err)
+	}
+
+	// Index teks PDF untuk pencarian full-text
+	if err := indexPDFText(ctx, *v); err != nil {
+		return fmt.Errorf("gagal mengindex teks PDF: %w", err)
+	}
+	return nil
+}
+
+// setProcessingStatus memperbarui status pemrosesan dokumen, hanya jika v
+// masih versi aktif (versi baru bisa saja sudah diupload)
+func setProcessingStatus(ctx context.Context, v models.DocumentVersion, status, message string) {
+	var processingError *string
+	if message != "" {
+		processingError = &message
+	}
+
+	_, err := config.DB.Exec(ctx,
+		`UPDATE documents SET processing_status = $1, processing_error = $2
+		 WHERE id = $3 AND current_version_id = $4`,
+		status, processingError, v.DocumentID, v.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Gagal memperbarui status pemrosesan", "document_id", v.DocumentID, "error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"repository-un/internal/metrics"
+	"repository-un/internal/middleware"
+	"repository-un/internal/router"
+	"repository-un/internal/workflow"
+)
+
+// Routes mendaftarkan semua endpoint API. Request ID, access log, metric
+// dan CORS dipasang untuk semua request; autentikasi dipasang per grup route.
+func Routes() http.Handler {
+	rt := router.New()
+	rt.Use(middleware.RequestIDMiddleware, middleware.AccessLogMiddleware,
+		middleware.MetricsMiddleware, middleware.CORSMiddleware)
+
+	// Path parameter bertipe, nilai yang tidak valid dijawab 404
+	rt.Param("id", router.UUID)
+	rt.Param("number", router.PositiveInt)
+	rt.Param("page", router.PositiveInt)
+	rt.Param("chunk", router.PositiveInt)
+	rt.Param("action", workflow.IsAction)
+
+	// Grup route berdasarkan kebutuhan login
+	public := rt
+	auth := rt.With(middleware.AuthMiddleware)
+	admin := rt.With(middleware.AdminMiddleware)
+
+	// Token opsional: anonim hanya melihat dokumen published,
+	// draft hanya untuk pemilik dan admin
+	optional := rt.With(middleware.OptionalAuthMiddleware)
+
+	// --- Auth ---
+	public.Post("/api/auth/login", LoginHandler)
+	public.Post("/api/auth/register", RegisterHandler)
+	public.Post("/api/auth/refresh", RefreshHandler)
+	public.Post("/api/auth/logout", LogoutHandler)
+	auth.Get("/api/auth/me", GetMeHandler)
+	public.Get("/.well-known/jwks.json", JWKSHandler)
+
+	// --- Users (admin) ---
+	admin.Get("/api/users", listUsers)
+	admin.Post("/api/users", createUser)
+	admin.Get("/api/users/{id}", getUserById)
+	admin.Put("/api/users/{id}", updateUser)
+	admin.Delete("/api/users/{id}", deleteUser)
+
+	// --- Audit (admin) ---
+	admin.Get("/api/audit", listAuditEvents)
+
+	// --- Karantina (admin) ---
+	// File yang terdeteksi malware atau gagal dipindai
+	admin.Get("/api/quarantine", listQuarantine)
+
+	// --- Documents ---
+	auth.Post("/uploads", UploadHandler)
+	optional.Get("/api/documents", listDocuments)
+	optional.Post("/api/documents", createDocument)
+	optional.Get("/api/documents/search", SearchDocumentsHandler)
+	optional.Get("/api/documents/{id}", getDocumentById)
+	optional.Put("/api/documents/{id}", updateDocument)
+	optional.Delete("/api/documents/{id}", deleteDocument)
+	optional.Get("/api/documents/{id}/pages", DocumentPagesHandler)
+	optional.Post("/api/documents/{id}/reprocess", reprocessDocument)
+
+	// Upload bertahap (resumable) untuk file besar
+	auth.Post("/api/uploads", createUpload)
+	auth.Get("/api/uploads/{id}", getUpload)
+	auth.Delete("/api/uploads/{id}", abortUpload)
+	auth.Put("/api/uploads/{id}/chunks/{chunk}", uploadChunk)
+	auth.Post("/api/uploads/{id}/complete", completeUpload)
+
+	// Versi file dokumen
+	optional.Get("/api/documents/{id}/versions", listVersions)
+	optional.Get("/api/documents/{id}/versions/{number}/download", downloadVersion)
+	optional.Get("/api/documents/{id}/versions/{number}/pages", listVersionPages)
+	optional.Post("/api/documents/{id}/versions/{number}/restore", restoreVersion)
+
+	// Alur editorial status dokumen (submit, publish, reject, ...)
+	optional.Get("/api/documents/{id}/history", listStatusHistory)
+	optional.Post("/api/documents/{id}/{action}", transitionDocument)
+
+	// --- OAI-PMH ---
+	// Harvesting metadata dokumen published oleh indexer
+	public.Get("/oai", OAIHandler)
+	public.Post("/oai", OAIHandler)
+
+	// --- Files ---
+	// Download dan preview file, aturan akses sama dengan dokumen
+	optional.Get("/download/{id}", DownloadHandler)
+	optional.Get("/preview/split/{id}/{path...}", PreviewSplitHandler)
+	optional.Get("/preview/image/{id}/{page}", PreviewImageHandler)
+	optional.Get("/split/{id}/{path...}", SplitFileHandler)
+
+	// --- Monitoring ---
+	// Liveness dan readiness publik, metric Prometheus hanya untuk
+	// scraper dengan METRICS_TOKEN atau admin
+	public.Get("/healthz", HealthzHandler)
+	public.Get("/readyz", ReadyzHandler)
+	rt.With(middleware.MetricsAuthMiddleware).Get("/metrics", metrics.Handler().ServeHTTP)
+
+	return rt
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/jobs"
+	"repository-un/internal/models"
+	"repository-un/internal/storage"
+)
+
+// Hasil pemindaian malware file versi. Hanya file clean yang bisa didownload.
+const (
+	ScanPending  = "pending"
+	ScanClean    = "clean"
+	ScanInfected = "infected"
+	ScanError    = "error"
+)
+
+// quarantineError menandai file yang dikarantina. Pemrosesan berhenti
+// dan job tidak diulang.
+type quarantineError struct {
+	reason string
+}
+
+func (e *quarantineError) Error() string { return e.reason }
+
+// scanVersionFile memindai file versi v sebelum diproses. File yang
+// terinfeksi, atau yang tetap gagal dipindai pada percobaan terakhir,
+// dikarantina dan menghasilkan *quarantineError.
+func scanVersionFile(ctx context.Context, v models.DocumentVersion, lastAttempt bool) error {
+	status, reason, err := scanFile(ctx, fileKey(v.FilePath), lastAttempt)
+	if err != nil {
+		return err
+	}
+	if status != ScanClean {
+		return quarantine(ctx, v, status, reason)
+	}
+	return setScanStatus(ctx, v.ID, ScanClean, nil)
+}
+
+// scanFile memindai file key dan menentukan scan_status-nya beserta
+// alasannya (nama malware atau pesan error scanner). Error berarti file
+// belum bisa diberi status dan job perlu dicoba ulang atau dihentikan.
+func scanFile(ctx context.Context, key string, lastAttempt bool) (status, reason string, err error) {
+	f, _, err := config.Storage.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotExist) {
+		return "", "", jobs.Permanent(fmt.Errorf("file tidak ditemukan: %w", err))
+	}
+	if err != nil {
+		return "", "", err
+	}
+	result, err := config.Scanner.Scan(ctx, f)
+	f.Close()
+
+	switch {
+	case err != nil && !lastAttempt:
+		// Daemon scanner bisa saja sedang restart, dicoba ulang oleh worker
+		return "", "", fmt.Errorf("gagal memindai file: %w", err)
+	case err != nil:
+		return ScanError, err.Error(), nil
+	case result.Infected:
+		return ScanInfected, result.Signature, nil
+	}
+	return ScanClean, "", nil
+}
+
+// quarantine mencatat file versi v sebagai infected atau error
+func quarantine(ctx context.Context, v models.DocumentVersion, status, reason string) error {
+	slog.WarnContext(ctx, "File dikarantina", "document_id", v.DocumentID, "version_id", v.ID,
+		"scan_status", status, "reason", reason)
+
+	if err := setScanStatus(ctx, v.ID, status, &reason); err != nil {
+		return err
+	}
+	return newQuarantineError(status, reason)
+}
+
+// newQuarantineError membuat alasan karantina yang ditampilkan sebagai
+// processing_error dokumen
+func newQuarantineError(status, reason string) *quarantineError {
+	if status == ScanInfected {
+		return &quarantineError{reason: "File terdeteksi malware: " + reason}
+	}
+	return &quarantineError{reason: "File tidak dapat dipindai: " + reason}
+}
+
+// setScanStatus menyimpan hasil pemindaian file versi
+func setScanStatus(ctx context.Context, versionID, status string, result *string) error {
+	_, err := config.DB.Exec(ctx,
+		`UPDATE document_versions SET scan_status = $1, scan_result = $2, scanned_at = NOW() WHERE id = $3`,
+		status, result, versionID)
+	return err
+}
+
+// checkScanStatus memastikan file sudah dipindai dan bersih sebelum
+// didownload. Mengembalikan false jika response error sudah ditulis.
+func checkScanStatus(w http.ResponseWriter, r *http.Request, status string) bool {
+	switch status {
+	case ScanClean:
+		return true
+	case ScanPending:
+		apierror.Write(w, r, errFileScanPending)
+	default:
+		apierror.Write(w, r, errFileQuarantined)
+	}
+	return false
+}
+
+// listQuarantine mengambil file yang dikarantina, terbaru lebih dulu.
+// Query status (infected atau error) memfilter hasil.
+// GET /api/quarantine
+func listQuarantine(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, limit, err := parsePagination(q)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	where := ` WHERE v.scan_status IN ('infected', 'error')`
+	args := []interface{}{}
+	if status := q.Get("status"); status != "" {
+		if status != ScanInfected && status != ScanError {
+			apierror.Write(w, r, errInvalidQuery.WithFields(apierror.Field("status", "invalid",
+				"harus infected atau error", "must be infected or error")))
+			return
+		}
+		where = ` WHERE v.scan_status = $1`
+		args = append(args, status)
+	}
+
+	var total int
+	err = config.DB.QueryRow(r.Context(),
+		`SELECT COUNT(*) FROM document_versions v`+where, args...).Scan(&total)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := config.DB.Query(r.Context(),
+		fmt.Sprintf(`SELECT d.id, d.judul, d.owner_id, v.id, v.version_number, v.original_name,
+		        v.file_size, v.mime_type, v.scan_status, COALESCE(v.scan_result, ''), v.scanned_at,
+		        d.current_version_id IS NOT DISTINCT FROM v.id
+		 FROM document_versions v JOIN documents d ON d.id = v.document_id%s
+		 ORDER BY v.scanned_at DESC NULLS LAST, v.id
+		 LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args)),
+		args...)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+	defer rows.Close()
+
+	files := []models.QuarantinedFile{}
+	for rows.Next() {
+		var f models.QuarantinedFile
+		err := rows.Scan(&f.DocumentID, &f.Title, &f.OwnerID, &f.VersionID, &f.VersionNumber,
+			&f.OriginalName, &f.FileSize, &f.MimeType, &f.ScanStatus, &f.ScanResult, &f.ScannedAt,
+			&f.IsCurrent)
+		if err != nil {
+			apierror.Write(w, r, errReadFailed)
+			return
+		}
+		files = append(files, f)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.QuarantineList{
+		Data:       files,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	})
+}
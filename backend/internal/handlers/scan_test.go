@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"repository-un/internal/config"
+	"repository-un/internal/jobs"
+	"repository-un/internal/scanner"
+	"repository-un/internal/storage"
+)
+
+// stubScanner mengembalikan hasil tetap dan mencatat isi yang dipindai
+type stubScanner struct {
+	result  scanner.Result
+	err     error
+	scanned string
+}
+
+func (s *stubScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	data, _ := io.ReadAll(r)
+	s.scanned = string(data)
+	return s.result, s.err
+}
+
+func (s *stubScanner) Check(ctx context.Context) error { return nil }
+
+func TestScanFile(t *testing.T) {
+	local, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldStorage, oldScanner := config.Storage, config.Scanner
+	t.Cleanup(func() { config.Storage, config.Scanner = oldStorage, oldScanner })
+	config.Storage = local
+
+	ctx := context.Background()
+	const key = "blobs/ab/abc.pdf"
+	if err := local.Put(ctx, key, strings.NewReader("%PDF-1.7"), 8, "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	down := errors.New("clamd: connection refused")
+	tests := []struct {
+		name        string
+		key         string
+		result      scanner.Result
+		err         error
+		lastAttempt bool
+		wantStatus  string
+		wantReason  string
+		wantRetry   bool // error biasa, dicoba ulang oleh worker
+	}{
+		{"clean", key, scanner.Result{}, nil, false, ScanClean, "", false},
+		{"infected", key, scanner.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil, false,
+			ScanInfected, "Eicar-Test-Signature", false},
+		{"scanner down", key, scanner.Result{}, down, false, "", "", true},
+		{"scanner down, last attempt", key, scanner.Result{}, down, true, ScanError, down.Error(), false},
+		{"missing file", "blobs/ab/none.pdf", scanner.Result{}, nil, false, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &stubScanner{result: tt.result, err: tt.err}
+			config.Scanner = s
+
+			status, reason, err := scanFile(ctx, tt.key, tt.lastAttempt)
+			if status != tt.wantStatus || reason != tt.wantReason {
+				t.Errorf("scanFile = %q, %q, want %q, %q", status, reason, tt.wantStatus, tt.wantReason)
+			}
+			switch {
+			case tt.wantRetry:
+				if err == nil || jobs.IsPermanent(err) {
+					t.Errorf("error = %v, want error yang dicoba ulang", err)
+				}
+			case tt.wantStatus == "":
+				if !jobs.IsPermanent(err) {
+					t.Errorf("error = %v, want jobs.Permanent", err)
+				}
+			case err != nil:
+				t.Errorf("error = %v", err)
+			}
+			if tt.wantStatus != "" && s.scanned != "%PDF-1.7" {
+				t.Errorf("isi yang dipindai = %q", s.scanned)
+			}
+		})
+	}
+}
+
+func TestQuarantineError(t *testing.T) {
+	var err error = newQuarantineError(ScanInfected, "Eicar-Test-Signature")
+
+	// processDocumentJob mengenali karantina lewat errors.As dan tidak
+	// mengulang job
+	var quarantined *quarantineError
+	if !errors.As(err, &quarantined) {
+		t.Fatal("errors.As gagal mengenali *quarantineError")
+	}
+	if want := "File terdeteksi malware: Eicar-Test-Signature"; quarantined.reason != want {
+		t.Errorf("reason = %q, want %q", quarantined.reason, want)
+	}
+	if got := newQuarantineError(ScanError, "timeout").Error(); got != "File tidak dapat dipindai: timeout" {
+		t.Errorf("reason = %q", got)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+	"repository-un/internal/utils"
+)
+
+// Penanda awal/akhir highlight dari ts_headline. Memakai karakter kontrol
+// agar snippet bisa di-escape dulu sebelum diganti dengan <mark>.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// headlineOptions adalah opsi ts_headline untuk snippet hasil pencarian
+var headlineOptions = fmt.Sprintf(
+	`StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "`,
+	highlightStart, highlightStop)
+
+// SearchDocumentsHandler menangani pencarian full-text dokumen
+// GET /api/documents/search?q=...&page=1&limit=20
+//
+// Pencarian meliputi judul, penulis dan teks hasil ekstraksi PDF dari versi
+// aktif dokumen. Query mendukung sintaks websearch ("frasa", OR, -kata).
+func SearchDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		apierror.Write(w, r, errInvalidQuery.WithFields(apierror.Required("q")))
+		return
+	}
+
+	page, limit, err := parsePagination(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Hanya dokumen yang boleh dilihat pemanggil ($1 = query, $2.. = akses)
+	visible, visibleArgs := currentViewer(r).visibleCondition("d.", 2)
+	args := append([]interface{}{query}, visibleArgs...)
+
+	var total int
+	err = config.DB.QueryRow(r.Context(),
+		`WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query)
+		 SELECT COUNT(*)
+		 FROM documents d CROSS JOIN q
+		 WHERE `+visible+`
+		   AND (d.search_vector @@ q.query
+		        OR EXISTS (
+		            SELECT 1 FROM document_page_texts t
+		            WHERE t.version_id = d.current_version_id AND t.search_vector @@ q.query
+		        ))`, args...).Scan(&total)
+	if err != nil {
+		apierror.Write(w, r, errSearchFailed)
+		return
+	}
+
+	// Ranking dan halaman terbaik dihitung dulu, snippet hanya dibuat
+	// untuk dokumen di halaman hasil yang diminta
+	rows, err := config.DB.Query(r.Context(),
+		`WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query),
+		 hits AS (
+		    SELECT d.id,
+		           ts_rank(d.search_vector, q.query) * 2 + COALESCE(p.rank, 0) AS rank,
+		           p.page_number
+		    FROM documents d
+		    CROSS JOIN q
+		    LEFT JOIN LATERAL (
+		        SELECT t.page_number, ts_rank(t.search_vector, q.query) AS rank
+		        FROM document_page_texts t
+		        WHERE t.version_id = d.current_version_id AND t.search_vector @@ q.query
+		        ORDER BY rank DESC, t.page_number
+		        LIMIT 1
+		    ) p ON true
+		    WHERE `+visible+` AND (d.search_vector @@ q.query OR p.page_number IS NOT NULL)
+		    ORDER BY rank DESC, d.created_at DESC
+		    LIMIT `+placeholder(len(args)+1)+` OFFSET `+placeholder(len(args)+2)+`
+		 )
+		 SELECT d.id, d.judul, d.penulis, d.jenis_file, d.status, d.processing_status, d.created_at, d.updated_at,
+		        h.rank,
+		        CASE WHEN t.content IS NOT NULL
+		             THEN ts_headline('simple', t.content, q.query, `+placeholder(len(args)+3)+`)
+		             ELSE ts_headline('simple', d.judul || ' - ' || d.penulis, q.query, `+placeholder(len(args)+3)+`)
+		        END,
+		        h.page_number
+		 FROM hits h
+		 JOIN documents d ON d.id = h.id
+		 CROSS JOIN q
+		 LEFT JOIN document_page_texts t
+		        ON t.version_id = d.current_version_id AND t.page_number = h.page_number
+		 ORDER BY h.rank DESC, d.created_at DESC`,
+		append(args, limit, (page-1)*limit, headlineOptions)...)
+	if err != nil {
+		apierror.Write(w, r, errSearchFailed)
+		return
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+
+	for rows.Next() {
+		var res models.SearchResult
+		err := rows.Scan(
+			&res.ID,
+			&res.Judul,
+			&res.Penulis,
+			&res.JenisFile,
+			&res.Status,
+			&res.ProcessingStatus,
+			&res.CreatedAt,
+			&res.UpdatedAt,
+			&res.Rank,
+			&res.Snippet,
+			&res.Page,
+		)
+		if err != nil {
+			apierror.Write(w, r, errReadFailed)
+			return
+		}
+		res.Snippet = highlightSnippet(res.Snippet)
+		results = append(results, res)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SearchResponse{
+		Query:   query,
+		Results: results,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// placeholder mengembalikan placeholder parameter query ke-n ($n)
+func placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// highlightSnippet meng-escape HTML pada snippet lalu mengganti penanda
+// highlight dengan tag <mark>, sehingga aman ditampilkan sebagai HTML
+func highlightSnippet(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, highlightStart, "<mark>")
+	return strings.ReplaceAll(s, highlightStop, "</mark>")
+}
+
+// indexPDFText mengekstrak teks PDF dari versi dokumen dan menyimpannya
+// per halaman untuk pencarian full-text
+func indexPDFText(ctx context.Context, v models.DocumentVersion) error {
+	pages, err := utils.ExtractPDFText(ctx, config.Storage, fileKey(v.FilePath))
+	if err != nil {
+		return err
+	}
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM document_page_texts WHERE version_id = $1`, v.ID)
+	if err != nil {
+		return err
+	}
+
+	for i, content := range pages {
+		if content == "" {
+			continue
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO document_page_texts (version_id, page_number, content) VALUES ($1, $2, $3)`,
+			v.ID, i+1, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"repository-un/internal/config"
+	"repository-un/internal/middleware"
+	"repository-un/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// refreshTokenTTL adalah masa berlaku refresh token. Setiap refresh
+// menghasilkan token baru dengan masa berlaku penuh.
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+// errInvalidRefreshToken dikembalikan jika refresh token tidak dikenal,
+// kedaluwarsa atau sudah dicabut
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+// hashRefreshToken mengembalikan hash SHA-256 (hex) refresh token.
+// Hanya hash yang disimpan di database.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken membuat refresh token acak 256 bit
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// issueSession membuat access token dan refresh token baru untuk user.
+// familyID kosong berarti sesi baru (login/register); refresh memakai
+// family yang sama agar pemakaian ulang token lama bisa dideteksi.
+// Mengembalikan response dan id baris refresh token yang baru.
+func issueSession(ctx context.Context, db dbExecutor, r *http.Request, user models.UserResponse, tokenVersion int, familyID string) (models.AuthResponse, string, error) {
+	token, err := middleware.GenerateToken(user.ID, user.Email, user.Role, tokenVersion)
+	if err != nil {
+		return models.AuthResponse{}, "", err
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return models.AuthResponse{}, "", err
+	}
+
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+
+	id := uuid.New().String()
+	_, err = db.Exec(ctx,
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, user_agent, ip_address)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		id, user.ID, familyID, hashRefreshToken(refreshToken), time.Now().Add(refreshTokenTTL),
+		truncate(r.UserAgent(), 512), clientIP(r),
+	)
+	if err != nil {
+		return models.AuthResponse{}, "", err
+	}
+
+	return models.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+		User:         user,
+	}, id, nil
+}
+
+// rotateRefreshToken menukar refresh token dengan pasangan token baru.
+// Token yang sudah dicabut tetapi dipakai lagi dianggap dicuri sehingga
+// seluruh family-nya ikut dicabut.
+func rotateRefreshToken(ctx context.Context, r *http.Request, refreshToken string) (models.AuthResponse, error) {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id, familyID string
+	var expiresAt time.Time
+	var revokedAt *time.Time
+	var user models.UserResponse
+	var tokenVersion int
+	err = tx.QueryRow(ctx,
+		`SELECT rt.id, rt.family_id, rt.expires_at, rt.revoked_at,
+		        u.id, u.name, u.email, u.role, u.created_at, u.updated_at, u.token_version
+		 FROM refresh_tokens rt
+		 JOIN users u ON u.id = rt.user_id
+		 WHERE rt.token_hash = $1
+		 FOR UPDATE OF rt`, hashRefreshToken(refreshToken)).Scan(
+		&id, &familyID, &expiresAt, &revokedAt,
+		&user.ID, &user.Name, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt, &tokenVersion,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.AuthResponse{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	if revokedAt != nil {
+		if err := revokeRefreshFamily(ctx, tx, familyID); err != nil {
+			return models.AuthResponse{}, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return models.AuthResponse{}, err
+		}
+		return models.AuthResponse{}, errInvalidRefreshToken
+	}
+
+	if time.Now().After(expiresAt) {
+		return models.AuthResponse{}, errInvalidRefreshToken
+	}
+
+	response, newID, err := issueSession(ctx, tx, r, user, tokenVersion, familyID)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW(), replaced_by = $1 WHERE id = $2`, newID, id)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.AuthResponse{}, err
+	}
+	return response, nil
+}
+
+// revokeRefreshFamily mencabut semua refresh token dalam satu family (sesi)
+func revokeRefreshFamily(ctx context.Context, db dbExecutor, familyID string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`,
+		familyID)
+	return err
+}
+
+// revokeUserSessions mencabut semua sesi user: versi token dinaikkan
+// sehingga semua access token lama ditolak, dan semua refresh token dicabut.
+// Dipakai saat logout dari semua perangkat dan saat role/email/password berubah.
+func revokeUserSessions(ctx context.Context, db dbExecutor, userID string) error {
+	_, err := db.Exec(ctx,
+		`UPDATE users SET token_version = token_version + 1 WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(ctx,
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID)
+	return err
+}
+
+// cleanupExpiredTokens menghapus token yang sudah kedaluwarsa. Refresh
+// token disimpan sehari lebih lama agar pemakaian ulang masih terdeteksi.
+func cleanupExpiredTokens(ctx context.Context) {
+	config.DB.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	config.DB.Exec(ctx, `DELETE FROM refresh_tokens WHERE expires_at < NOW() - INTERVAL '1 day'`)
+}
+
+// clientIP mengembalikan alamat IP client dari RemoteAddr
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return truncate(r.RemoteAddr, 64)
+	}
+	return truncate(host, 64)
+}
+
+// truncate memotong s menjadi paling banyak n byte tanpa memotong di tengah
+// karakter UTF-8. Byte yang bukan UTF-8 valid (misalnya dari header
+// User-Agent) dibuang karena ditolak PostgreSQL.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "")
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
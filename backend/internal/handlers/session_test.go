@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		n    int
+		want string
+	}{
+		{"short", "Mozilla/5.0", 512, "Mozilla/5.0"},
+		{"ascii", "abcdef", 3, "abc"},
+		{"exact", "abc", 3, "abc"},
+		// "é" dua byte, "語" tiga byte
+		{"cut inside two-byte rune", "aé", 2, "a"},
+		{"cut inside three-byte rune", "ab語", 4, "ab"},
+		{"rune ends at limit", "ab語", 5, "ab語"},
+		{"invalid utf-8 dropped", "ab\xffcd", 10, "abcd"},
+		{"zero", "語", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.in, tt.n)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateUserAgent(t *testing.T) {
+	// Karakter multi-byte yang melewati byte ke-512 tidak boleh terpotong
+	ua := strings.Repeat("a", 511) + "ü" + strings.Repeat("b", 10)
+	got := truncate(ua, 512)
+	if !utf8.ValidString(got) || len(got) > 512 {
+		t.Fatalf("truncate menghasilkan %d byte, valid UTF-8 = %v", len(got), utf8.ValidString(got))
+	}
+	if got != strings.Repeat("a", 511) {
+		t.Errorf("truncate = %q...", got[500:])
+	}
+}
@@ -0,0 +1,724 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/jobs"
+	"repository-un/internal/metrics"
+	"repository-un/internal/models"
+	"repository-un/internal/router"
+	"repository-un/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Upload bertahap (resumable) untuk file besar:
+//
+//  1. POST   /api/uploads                       memulai sesi, menjawab chunk_size dan total_chunks
+//  2. PUT    /api/uploads/:id/chunks/:chunk     mengirim potongan ke-chunk (mulai 1) dengan header X-Chunk-SHA256
+//  3. GET    /api/uploads/:id                   melihat potongan yang sudah/belum diterima untuk melanjutkan upload
+//  4. POST   /api/uploads/:id/complete          menggabungkan potongan menjadi dokumen baru atau versi baru
+//
+// Potongan disimpan di storage "chunks/<id>/<nomor>" sampai upload selesai,
+// dibatalkan (DELETE /api/uploads/:id) atau sesinya kedaluwarsa.
+
+// jobExpireUpload adalah tipe job penghapusan sesi upload yang kedaluwarsa
+const jobExpireUpload = "expire_upload"
+
+// chunkChecksumHeader berisi SHA-256 (hex) isi potongan yang dikirim
+const chunkChecksumHeader = "X-Chunk-SHA256"
+
+// expireUploadPayload adalah payload job expire_upload
+type expireUploadPayload struct {
+	UploadID string `json:"upload_id"`
+}
+
+// uploadSession adalah sesi upload beserta status penggabungannya
+type uploadSession struct {
+	models.UploadSession
+	Completing bool
+}
+
+// chunkLength mengembalikan ukuran potongan ke-n, potongan terakhir
+// berisi sisa file
+func (s uploadSession) chunkLength(n int) int64 {
+	return min(s.ChunkSize, s.TotalSize-int64(n-1)*s.ChunkSize)
+}
+
+// chunkDir mengembalikan lokasi potongan sesi upload id di storage
+func chunkDir(id string) string {
+	return storage.Join("chunks", id)
+}
+
+// chunkKey mengembalikan key potongan ke-n sesi upload id
+func chunkKey(id string, n int) string {
+	return storage.Join(chunkDir(id), strconv.Itoa(n))
+}
+
+// countChunks mengembalikan jumlah potongan untuk file berukuran size
+func countChunks(size, chunkSize int64) int {
+	return int((size + chunkSize - 1) / chunkSize)
+}
+
+// isSHA256 mengecek checksum SHA-256 dalam bentuk hex huruf kecil
+func isSHA256(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == sha256.Size && s == strings.ToLower(s)
+}
+
+// uploadFileName membersihkan nama file dari client: path dibuang dan hasilnya
+// dipotong ke 255 byte (batas kolom file_name) tanpa merusak karakter UTF-8
+func uploadFileName(name string) string {
+	return truncate(originalName(strings.TrimSpace(name)), 255)
+}
+
+// createUpload memulai sesi upload bertahap. document_id diisi untuk
+// mengupload versi baru dokumen (hanya pemilik atau admin).
+// POST /api/uploads
+func createUpload(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, r, apierror.BadRequest)
+		return
+	}
+
+	req.FileName = uploadFileName(req.FileName)
+	req.SHA256 = strings.ToLower(strings.TrimSpace(req.SHA256))
+
+	fields := missingFields("file_name", req.FileName)
+	if req.Size <= 0 {
+		fields = append(fields, apierror.Field("size", "invalid", "harus lebih dari 0", "must be greater than 0"))
+	}
+	if req.SHA256 != "" && !isSHA256(req.SHA256) {
+		fields = append(fields, apierror.Invalid("sha256"))
+	}
+	if req.DocumentID != "" && !router.UUID(req.DocumentID) {
+		fields = append(fields, apierror.Invalid("document_id"))
+	}
+	if fields != nil {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(fields...))
+		return
+	}
+
+	// Batas per format baru dicek saat complete, di sini cukup batas
+	// terbesar dari semua format yang diizinkan
+	maxSize := min(maxAllowedTypeSize(), config.App.Upload.MaxSize)
+	if req.Size > int64(maxSize) {
+		apierror.Write(w, r, errUploadTooLarge.WithMessage(apierror.Textf(
+			"Ukuran file maksimal %s", "Maximum file size is %s", maxSize)))
+		return
+	}
+
+	s := models.UploadSession{
+		ID:          uuid.New().String(),
+		FileName:    req.FileName,
+		ContentType: truncate(req.ContentType, 255),
+		TotalSize:   req.Size,
+		ChunkSize:   int64(config.App.Upload.ChunkSize),
+		CreatedAt:   time.Now(),
+	}
+	if req.DocumentID != "" {
+		if _, ok := authorizeContentEdit(w, r, req.DocumentID); !ok {
+			return
+		}
+		s.DocumentID = &req.DocumentID
+	}
+	if req.SHA256 != "" {
+		// Duplikat sudah bisa diketahui sebelum potongan pertama dikirim
+		if err := checkDuplicate(r.Context(), r, req.SHA256); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		s.SHA256 = &req.SHA256
+	}
+	s.ExpiresAt = s.CreatedAt.Add(config.App.Upload.SessionTTL)
+	s.TotalChunks = countChunks(s.TotalSize, s.ChunkSize)
+	s.ReceivedChunks = []int{}
+	for n := 1; n <= s.TotalChunks; n++ {
+		s.MissingChunks = append(s.MissingChunks, n)
+	}
+
+	tx, err := config.DB.Begin(r.Context())
+	if err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	_, err = tx.Exec(r.Context(),
+		`INSERT INTO upload_sessions (id, owner_id, document_id, file_name, content_type,
+		                              total_size, chunk_size, sha256, expires_at, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`,
+		s.ID, currentViewer(r).UserID, s.DocumentID, s.FileName, s.ContentType,
+		s.TotalSize, s.ChunkSize, s.SHA256, s.ExpiresAt, s.CreatedAt)
+	if err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+
+	// Sesi yang ditinggalkan client dihapus oleh job saat kedaluwarsa
+	_, err = jobs.EnqueueAt(r.Context(), tx, jobExpireUpload, expireUploadPayload{UploadID: s.ID}, s.ExpiresAt)
+	if err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// loadUploadSession mengambil sesi upload {id} milik user yang login
+// beserta daftar potongan yang sudah diterima. Mengembalikan false jika
+// response error sudah ditulis.
+func loadUploadSession(w http.ResponseWriter, r *http.Request) (uploadSession, bool) {
+	var s uploadSession
+	err := config.DB.QueryRow(r.Context(),
+		`SELECT id, document_id, file_name, content_type, total_size, chunk_size,
+		        sha256, completing, expires_at, created_at
+		 FROM upload_sessions
+		 WHERE id = $1 AND owner_id = $2 AND expires_at > NOW()`,
+		r.PathValue("id"), currentViewer(r).UserID).Scan(
+		&s.ID, &s.DocumentID, &s.FileName, &s.ContentType, &s.TotalSize, &s.ChunkSize,
+		&s.SHA256, &s.Completing, &s.ExpiresAt, &s.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		apierror.Write(w, r, errUploadNotFound)
+		return s, false
+	}
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return s, false
+	}
+
+	rows, err := config.DB.Query(r.Context(),
+		`SELECT number FROM upload_chunks WHERE session_id = $1 ORDER BY number`, s.ID)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return s, false
+	}
+	received := map[int]bool{}
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			rows.Close()
+			apierror.Write(w, r, errFetchFailed)
+			return s, false
+		}
+		received[n] = true
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return s, false
+	}
+
+	s.TotalChunks = countChunks(s.TotalSize, s.ChunkSize)
+	s.ReceivedChunks = []int{}
+	s.MissingChunks = []int{}
+	for n := 1; n <= s.TotalChunks; n++ {
+		if received[n] {
+			s.ReceivedChunks = append(s.ReceivedChunks, n)
+		} else {
+			s.MissingChunks = append(s.MissingChunks, n)
+		}
+	}
+	return s, true
+}
+
+// getUpload menampilkan status sesi upload, dipakai client untuk
+// melanjutkan upload yang terputus
+// GET /api/uploads/:id
+func getUpload(w http.ResponseWriter, r *http.Request) {
+	s, ok := loadUploadSession(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.UploadSession)
+}
+
+// byteCounter menghitung jumlah byte yang ditulis
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// uploadChunk menerima satu potongan file sebagai body mentah. Potongan
+// yang dikirim ulang menimpa potongan sebelumnya, dan setiap potongan
+// memperpanjang umur sesi.
+// PUT /api/uploads/:id/chunks/:chunk
+func uploadChunk(w http.ResponseWriter, r *http.Request) {
+	s, ok := loadUploadSession(w, r)
+	if !ok {
+		return
+	}
+	if s.Completing {
+		apierror.Write(w, r, errUploadCompleting)
+		return
+	}
+
+	n := router.IntParam(r, "chunk")
+	if n > s.TotalChunks {
+		apierror.Write(w, r, errChunkOutOfRange)
+		return
+	}
+
+	checksum := strings.ToLower(strings.TrimSpace(r.Header.Get(chunkChecksumHeader)))
+	if !isSHA256(checksum) {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(apierror.Field(chunkChecksumHeader, "invalid",
+			"wajib berisi SHA-256 (hex) isi potongan", "must contain the hex SHA-256 of the chunk")))
+		return
+	}
+
+	expected := s.chunkLength(n)
+	sizeMismatch := errChunkSizeMismatch.WithMessage(apierror.Textf(
+		"Ukuran potongan %d harus %d byte", "Chunk %d must be %d bytes", n, expected))
+	if r.ContentLength >= 0 && r.ContentLength != expected {
+		apierror.Write(w, r, sizeMismatch)
+		return
+	}
+
+	// Potongan ditulis ke key sementara dulu lalu dipindah setelah sesi
+	// dipastikan belum digabung, lihat commitChunk
+	key := storage.Join(chunkDir(s.ID), fmt.Sprintf("%d.%s.part", n, uuid.New()))
+	hash := sha256.New()
+	var size byteCounter
+	body := io.TeeReader(io.LimitReader(r.Body, expected), io.MultiWriter(hash, &size))
+
+	err := config.Storage.Put(r.Context(), key, body, expected, "application/octet-stream")
+
+	// Body yang lebih panjang dari ukuran potongan juga ditolak
+	extra, _ := r.Body.Read(make([]byte, 1))
+
+	ctx := context.WithoutCancel(r.Context())
+	if int64(size) != expected || extra > 0 {
+		config.Storage.Remove(ctx, key)
+		apierror.Write(w, r, sizeMismatch)
+		return
+	}
+	if err != nil {
+		config.Storage.Remove(ctx, key)
+		apierror.Write(w, r, errStoreFileFailed)
+		return
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		config.Storage.Remove(ctx, key)
+		apierror.Write(w, r, errChunkChecksum)
+		return
+	}
+
+	chunk := models.UploadChunk{Number: n, Size: expected, SHA256: checksum}
+	if err := commitChunk(ctx, s.ID, key, chunk); err != nil {
+		config.Storage.Remove(ctx, key)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = config.DB.QueryRow(ctx,
+		`UPDATE upload_sessions SET expires_at = NOW() + $2 * INTERVAL '1 second', updated_at = NOW()
+		 WHERE id = $1 RETURNING expires_at`,
+		s.ID, config.App.Upload.SessionTTL.Seconds()).Scan(&chunk.ExpiresAt)
+	if err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chunk)
+}
+
+// commitChunk memindah potongan dari key sementara ke lokasinya lalu
+// mencatatnya, selama sesi belum mulai digabung. Baris sesi dikunci
+// FOR SHARE sehingga completeUpload menunggu sampai potongan selesai
+// diganti dan tidak pernah membaca potongan yang setengah tertulis.
+func commitChunk(ctx context.Context, sessionID, tmpKey string, chunk models.UploadChunk) error {
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		return errUploadFailed
+	}
+	defer tx.Rollback(ctx)
+
+	var completing bool
+	err = tx.QueryRow(ctx,
+		`SELECT completing FROM upload_sessions WHERE id = $1 FOR SHARE`, sessionID).Scan(&completing)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errUploadNotFound
+	}
+	if err != nil {
+		return errUploadFailed
+	}
+	if completing {
+		return errUploadCompleting
+	}
+
+	if err := config.Storage.Move(ctx, tmpKey, chunkKey(sessionID, chunk.Number)); err != nil {
+		return errStoreFileFailed
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO upload_chunks (session_id, number, size, sha256) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (session_id, number)
+		 DO UPDATE SET size = EXCLUDED.size, sha256 = EXCLUDED.sha256, created_at = CURRENT_TIMESTAMP`,
+		sessionID, chunk.Number, chunk.Size, chunk.SHA256)
+	if err != nil {
+		return errUploadFailed
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return errUploadFailed
+	}
+	return nil
+}
+
+// abortUpload membatalkan sesi upload dan menghapus potongannya
+// DELETE /api/uploads/:id
+func abortUpload(w http.ResponseWriter, r *http.Request) {
+	s, ok := loadUploadSession(w, r)
+	if !ok {
+		return
+	}
+	if s.Completing {
+		apierror.Write(w, r, errUploadCompleting)
+		return
+	}
+
+	if err := removeUploadSession(context.WithoutCancel(r.Context()), s.ID); err != nil {
+		apierror.Write(w, r, errDeleteFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"message":"Upload dibatalkan"}`))
+}
+
+// completeUpload menggabungkan semua potongan menjadi satu file. Sesi
+// tanpa document_id membuat dokumen baru dengan metadata dari body
+// (sama dengan POST /api/documents versi JSON); sesi dengan document_id
+// menambahkan versi baru ke dokumen tersebut.
+// POST /api/uploads/:id/complete
+func completeUpload(w http.ResponseWriter, r *http.Request) {
+	s, ok := loadUploadSession(w, r)
+	if !ok {
+		return
+	}
+	if s.Completing {
+		apierror.Write(w, r, errUploadCompleting)
+		return
+	}
+	if len(s.MissingChunks) > 0 {
+		apierror.Write(w, r, errUploadIncomplete.WithMessage(apierror.Textf(
+			"Masih ada %d potongan file yang belum diupload", "%d chunks have not been uploaded yet",
+			len(s.MissingChunks))))
+		return
+	}
+
+	var req models.CreateDocumentRequest
+	if s.DocumentID == nil {
+		var err error
+		req, err = parseDocumentRequest(r)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if err := normalizeMetadata(&req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	} else if _, ok := authorizeContentEdit(w, r, *s.DocumentID); !ok {
+		return
+	}
+
+	// Tandai sesi sedang digabung agar potongan tidak berubah dan complete
+	// tidak berjalan dua kali. Umur sesi diperpanjang selama penggabungan.
+	tag, err := config.DB.Exec(r.Context(),
+		`UPDATE upload_sessions
+		 SET completing = TRUE, updated_at = NOW(),
+		     expires_at = GREATEST(expires_at, NOW() + $2 * INTERVAL '1 second')
+		 WHERE id = $1 AND NOT completing`,
+		s.ID, config.App.Upload.SessionTTL.Seconds())
+	if err != nil {
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		apierror.Write(w, r, errUploadCompleting)
+		return
+	}
+
+	// Penggabungan tetap diselesaikan walaupun client memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
+
+	stored, err := assembleUpload(ctx, s)
+	if err != nil {
+		releaseUpload(ctx, s.ID)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Potongan tetap disimpan agar client bisa complete ulang dengan
+	// allow_duplicate=true
+	if err := checkDuplicate(ctx, r, stored.SHA256); err != nil {
+		config.Storage.Remove(ctx, stored.Staging)
+		releaseUpload(ctx, s.ID)
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Dokumen atau versi baru dicatat dan sesi dihapus dalam satu transaksi
+	tx, err := beginFileTx(ctx, stored.Staging)
+	if err != nil {
+		releaseUpload(ctx, s.ID)
+		apierror.Write(w, r, errUploadFailed)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Transaksi dibatalkan dulu karena baris sesi masih terkunci oleh tx
+	fail := func(err error) {
+		tx.Rollback(ctx)
+		releaseUpload(ctx, s.ID)
+		apierror.Write(w, r, err)
+	}
+
+	details := map[string]interface{}{"file_name": stored.Name, "size": stored.Size, "upload_id": s.ID}
+	var id string
+	var version models.DocumentVersion
+	if s.DocumentID == nil {
+		id, version, err = createDocumentWithFile(ctx, tx, r, req, stored)
+		details["title"] = req.Title
+	} else {
+		id = *s.DocumentID
+		err = lockEditableDocument(ctx, tx, id)
+		if err == nil {
+			version, err = addDocumentVersion(ctx, tx, r, id, stored)
+		}
+		details["version_number"] = version.VersionNumber
+	}
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err := deleteUploadSession(ctx, tx, s.ID); err != nil {
+		fail(errUploadFailed)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		fail(errUploadFailed)
+		return
+	}
+
+	action := auditDocumentCreate
+	if s.DocumentID != nil {
+		action = auditDocumentUpdate
+	}
+	recordAudit(r, auditEvent{Action: action, TargetType: targetDocument, TargetID: id, Details: details})
+
+	doc, err := loadDocument(ctx, id)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(struct {
+		models.Document
+		VersionNumber int `json:"version_number"`
+	}{doc, version.VersionNumber})
+}
+
+// assembleUpload menggabungkan potongan sesi s menjadi satu file di
+// area staging sambil menghitung SHA-256 seluruh file. Format file dicek dari
+// potongan sebelum digabung.
+func assembleUpload(ctx context.Context, s uploadSession) (storedFile, error) {
+	t, err := detectFileType(chunkFile{ctx: ctx, session: s}, s.TotalSize)
+	if err != nil {
+		return storedFile{}, err
+	}
+
+	chunks := &chunkReader{ctx: ctx, session: s}
+	defer chunks.Close()
+
+	f, err := stageFile(ctx, chunks, s.FileName, s.TotalSize, t)
+	if err != nil {
+		slog.ErrorContext(ctx, "Gagal menggabungkan potongan upload", "upload_id", s.ID, "error", err)
+		return f, errStoreFileFailed
+	}
+
+	if s.SHA256 != nil && f.SHA256 != *s.SHA256 {
+		config.Storage.Remove(ctx, f.Staging)
+		return f, errUploadChecksum
+	}
+
+	metrics.UploadSize.Observe(float64(f.Size))
+	return f, nil
+}
+
+// chunkReader membaca potongan sesi upload secara berurutan seolah-olah
+// satu file, tanpa menyalin potongan ke memori
+type chunkReader struct {
+	ctx     context.Context
+	session uploadSession
+	next    int
+	current io.ReadCloser
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if c.next >= c.session.TotalChunks {
+				return 0, io.EOF
+			}
+			c.next++
+
+			rc, info, err := config.Storage.Open(c.ctx, chunkKey(c.session.ID, c.next))
+			if err != nil {
+				return 0, fmt.Errorf("gagal membuka potongan %d: %w", c.next, err)
+			}
+			if info.Size != c.session.chunkLength(c.next) {
+				rc.Close()
+				return 0, fmt.Errorf("ukuran potongan %d tidak sesuai", c.next)
+			}
+			c.current = rc
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			c.current.Close()
+			c.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close menutup potongan yang sedang dibaca
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	err := c.current.Close()
+	c.current = nil
+	return err
+}
+
+// chunkFile membaca potongan sesi upload pada posisi tertentu tanpa
+// menggabungkannya, dipakai untuk mendeteksi format file
+type chunkFile struct {
+	ctx     context.Context
+	session uploadSession
+}
+
+func (c chunkFile) ReadAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= c.session.TotalSize {
+			return n, io.EOF
+		}
+
+		number := int(pos/c.session.ChunkSize) + 1
+		start := pos - int64(number-1)*c.session.ChunkSize
+		end := min(int64(len(p)-n), c.session.chunkLength(number)-start)
+
+		rc, _, err := config.Storage.Open(c.ctx, chunkKey(c.session.ID, number))
+		if err != nil {
+			return n, fmt.Errorf("gagal membuka potongan %d: %w", number, err)
+		}
+		if _, err = rc.Seek(start, io.SeekStart); err == nil {
+			var m int
+			m, err = io.ReadFull(rc, p[n:n+int(end)])
+			n += m
+		}
+		rc.Close()
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// releaseUpload membuka kembali sesi setelah penggabungan gagal agar
+// client bisa mengirim ulang potongan atau mencoba complete lagi
+func releaseUpload(ctx context.Context, id string) {
+	_, err := config.DB.Exec(ctx,
+		`UPDATE upload_sessions SET completing = FALSE, updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "Gagal membuka kembali sesi upload", "upload_id", id, "error", err)
+	}
+}
+
+// removeUploadSession menghapus potongan lalu data sesi upload. Data sesi
+// baru dihapus setelah potongan terhapus agar tidak ada potongan yatim.
+func removeUploadSession(ctx context.Context, id string) error {
+	if err := config.Storage.RemoveAll(ctx, chunkDir(id)); err != nil {
+		return err
+	}
+	_, err := config.DB.Exec(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id)
+	return err
+}
+
+// deleteUploadSession menghapus data sesi upload di dalam tx. Potongan
+// dihapus setelah commit; sisa yang gagal dihapus dibersihkan oleh
+// "server gc".
+func deleteUploadSession(ctx context.Context, tx *fileTx, id string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id); err != nil {
+		return err
+	}
+	tx.RemoveAllOnCommit(chunkDir(id))
+	return nil
+}
+
+// expireUploadJob adalah handler job expire_upload. Sesi yang masih
+// diperpanjang oleh potongan baru dijadwalkan ulang sampai benar-benar
+// kedaluwarsa.
+func expireUploadJob(ctx context.Context, job jobs.Job) error {
+	var p expireUploadPayload
+	if err := job.Decode(&p); err != nil {
+		return jobs.Permanent(err)
+	}
+
+	var expiresAt time.Time
+	var expired bool
+	err := config.DB.QueryRow(ctx,
+		`SELECT expires_at, expires_at <= NOW() FROM upload_sessions WHERE id = $1`,
+		p.UploadID).Scan(&expiresAt, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Upload sudah selesai atau dibatalkan
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !expired {
+		_, err := jobs.EnqueueAt(ctx, config.DB, jobExpireUpload, p, expiresAt)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Menghapus sesi upload yang kedaluwarsa", "upload_id", p.UploadID)
+	return removeUploadSession(ctx, p.UploadID)
+}
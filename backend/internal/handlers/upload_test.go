@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestUploadFileName(t *testing.T) {
+	// 100 karakter "文" = 300 byte, melewati batas 255 byte di tengah karakter
+	long := strings.Repeat("文", 100) + ".pdf"
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "skripsi.pdf", "skripsi.pdf"},
+		{"unix path", "/home/user/skripsi.pdf", "skripsi.pdf"},
+		{"windows path", `C:\Users\budi\Tesis Akhir.pdf`, "Tesis Akhir.pdf"},
+		{"spaces", "  laporan.pdf  ", "laporan.pdf"},
+		{"multi-byte over limit", long, strings.Repeat("文", 85)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uploadFileName(tt.in)
+			if got != tt.want {
+				t.Errorf("uploadFileName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if len(got) > 255 || !utf8.ValidString(got) {
+				t.Errorf("uploadFileName(%q): %d byte, valid UTF-8 = %v", tt.in, len(got), utf8.ValidString(got))
+			}
+		})
+	}
+}
@@ -1,76 +1,43 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
 	"time"
 
+	"repository-un/internal/apierror"
 	"repository-un/internal/config"
-	"repository-un/internal/middleware"
 	"repository-un/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// UsersHandler menangani operasi list dan create user
-// GET /api/users - List semua user
-// POST /api/users - Buat user baru
-func UsersHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
-		return
-	}
-	middleware.EnableCORS(w)
-
-	switch r.Method {
-	case http.MethodGet:
-		listUsers(w, r)
-	case http.MethodPost:
-		createUser(w, r)
-	default:
-		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
-	}
-}
+// minPasswordLength adalah panjang minimal password user
+const minPasswordLength = 6
 
-// UserByIdHandler menangani operasi pada user tertentu
-// GET /api/users/:id - Get user by ID
-// PUT /api/users/:id - Update user
-// DELETE /api/users/:id - Delete user
-func UserByIdHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		middleware.EnableCORS(w)
-		return
-	}
-	middleware.EnableCORS(w)
-
-	id := strings.TrimPrefix(r.URL.Path, "/api/users/")
-	if id == "" {
-		http.Error(w, `{"error":"Invalid ID"}`, http.StatusBadRequest)
-		return
-	}
+// passwordTooShort adalah kesalahan field password yang kurang dari
+// minPasswordLength karakter
+func passwordTooShort() apierror.FieldError {
+	return apierror.Field("password", "too_short",
+		"minimal %d karakter", "must be at least %d characters", minPasswordLength)
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		getUserById(w, r, id)
-	case http.MethodPut:
-		updateUser(w, r, id)
-	case http.MethodDelete:
-		deleteUser(w, r, id)
-	default:
-		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
-	}
+// invalidRole adalah kesalahan field role selain admin dan user
+func invalidRole() apierror.FieldError {
+	return apierror.Field("role", "invalid", "harus 'admin' atau 'user'", "must be 'admin' or 'user'")
 }
 
 // listUsers mengambil semua user dari database
+// GET /api/users
 func listUsers(w http.ResponseWriter, r *http.Request) {
-	rows, err := config.DB.Query(context.Background(),
+	rows, err := config.DB.Query(r.Context(),
 		`SELECT id, name, email, role, created_at, updated_at 
 		 FROM users ORDER BY created_at DESC`)
 	if err != nil {
-		http.Error(w, `{"error":"Failed to fetch users"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errFetchFailed)
 		return
 	}
 	defer rows.Close()
@@ -81,7 +48,7 @@ func listUsers(w http.ResponseWriter, r *http.Request) {
 		var u models.UserResponse
 		err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt)
 		if err != nil {
-			http.Error(w, `{"error":"Failed to read user data"}`, http.StatusInternalServerError)
+			apierror.Write(w, r, errReadFailed)
 			return
 		}
 		users = append(users, u)
@@ -92,16 +59,18 @@ func listUsers(w http.ResponseWriter, r *http.Request) {
 }
 
 // getUserById mengambil user berdasarkan ID
-func getUserById(w http.ResponseWriter, r *http.Request, id string) {
+// GET /api/users/:id
+func getUserById(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
 	var u models.UserResponse
-	err := config.DB.QueryRow(context.Background(),
+	err := config.DB.QueryRow(r.Context(),
 		`SELECT id, name, email, role, created_at, updated_at 
 		 FROM users WHERE id = $1`, id).Scan(
 		&u.ID, &u.Name, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt,
 	)
 
 	if err != nil {
-		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+		apierror.Write(w, r, errUserNotFound)
 		return
 	}
 
@@ -110,20 +79,11 @@ func getUserById(w http.ResponseWriter, r *http.Request, id string) {
 }
 
 // createUser membuat user baru (admin only)
+// POST /api/users
 func createUser(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
-		return
-	}
-
-	if req.Name == "" || req.Email == "" || req.Password == "" {
-		http.Error(w, `{"error":"Name, email, and password are required"}`, http.StatusBadRequest)
-		return
-	}
-
-	if len(req.Password) < 6 {
-		http.Error(w, `{"error":"Password must be at least 6 characters"}`, http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest)
 		return
 	}
 
@@ -131,42 +91,52 @@ func createUser(w http.ResponseWriter, r *http.Request) {
 		req.Role = "user"
 	}
 
+	fields := missingFields("name", req.Name, "email", req.Email, "password", req.Password)
+	if req.Password != "" && len(req.Password) < minPasswordLength {
+		fields = append(fields, passwordTooShort())
+	}
 	if req.Role != "admin" && req.Role != "user" {
-		http.Error(w, `{"error":"Role must be 'admin' or 'user'"}`, http.StatusBadRequest)
+		fields = append(fields, invalidRole())
+	}
+	if fields != nil {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(fields...))
 		return
 	}
 
 	// Cek apakah email sudah terdaftar
 	var exists bool
-	config.DB.QueryRow(context.Background(),
+	config.DB.QueryRow(r.Context(),
 		`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, req.Email).Scan(&exists)
 
 	if exists {
-		http.Error(w, `{"error":"Email already registered"}`, http.StatusConflict)
+		apierror.Write(w, r, errEmailTaken)
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, `{"error":"Failed to process password"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errPasswordFailed)
 		return
 	}
 
 	id := uuid.New().String()
 	now := time.Now()
 
-	_, err = config.DB.Exec(context.Background(),
+	_, err = config.DB.Exec(r.Context(),
 		`INSERT INTO users (id, name, email, password, role, created_at, updated_at)
 		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 		id, req.Name, req.Email, string(hashedPassword), req.Role, now, now,
 	)
 
 	if err != nil {
-		http.Error(w, `{"error":"Failed to create user"}`, http.StatusInternalServerError)
+		apierror.Write(w, r, errCreateUserFailed)
 		return
 	}
 
+	recordAudit(r, auditEvent{Action: auditUserCreate, TargetType: targetUser, TargetID: id,
+		Details: map[string]interface{}{"email": req.Email, "role": req.Role}})
+
 	response := models.UserResponse{
 		ID:        id,
 		Name:      req.Name,
@@ -182,79 +152,99 @@ func createUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // updateUser mengupdate data user
-func updateUser(w http.ResponseWriter, r *http.Request, id string) {
+// PUT /api/users/:id
+func updateUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
 	var req models.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"Invalid request body"}`, http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest)
 		return
 	}
 
-	if req.Name == "" || req.Email == "" {
-		http.Error(w, `{"error":"Name and email are required"}`, http.StatusBadRequest)
-		return
+	fields := missingFields("name", req.Name, "email", req.Email)
+	if req.Password != "" && len(req.Password) < minPasswordLength {
+		fields = append(fields, passwordTooShort())
 	}
-
 	if req.Role != "" && req.Role != "admin" && req.Role != "user" {
-		http.Error(w, `{"error":"Role must be 'admin' or 'user'"}`, http.StatusBadRequest)
+		fields = append(fields, invalidRole())
+	}
+	if fields != nil {
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(fields...))
 		return
 	}
 
 	// Cek apakah user ada
-	var exists bool
-	config.DB.QueryRow(context.Background(),
-		`SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists)
+	var oldEmail, oldRole string
+	err := config.DB.QueryRow(r.Context(),
+		`SELECT email, role FROM users WHERE id = $1`, id).Scan(&oldEmail, &oldRole)
 
-	if !exists {
-		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+	if err != nil {
+		apierror.Write(w, r, errUserNotFound)
 		return
 	}
 
 	// Cek apakah email sudah dipakai user lain
 	var emailExists bool
-	config.DB.QueryRow(context.Background(),
+	config.DB.QueryRow(r.Context(),
 		`SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND id != $2)`, req.Email, id).Scan(&emailExists)
 
 	if emailExists {
-		http.Error(w, `{"error":"Email already in use"}`, http.StatusConflict)
+		apierror.Write(w, r, errEmailTaken)
 		return
 	}
 
 	now := time.Now()
 
 	if req.Password != "" {
-		if len(req.Password) < 6 {
-			http.Error(w, `{"error":"Password must be at least 6 characters"}`, http.StatusBadRequest)
-			return
-		}
-
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
-			http.Error(w, `{"error":"Failed to process password"}`, http.StatusInternalServerError)
+			apierror.Write(w, r, errPasswordFailed)
 			return
 		}
 
-		_, err = config.DB.Exec(context.Background(),
+		_, err = config.DB.Exec(r.Context(),
 			`UPDATE users SET name = $1, email = $2, password = $3, role = $4, updated_at = $5 WHERE id = $6`,
 			req.Name, req.Email, string(hashedPassword), req.Role, now, id,
 		)
 		if err != nil {
-			http.Error(w, `{"error":"Failed to update user"}`, http.StatusInternalServerError)
+			apierror.Write(w, r, errUpdateUserFailed)
 			return
 		}
 	} else {
-		_, err := config.DB.Exec(context.Background(),
+		_, err = config.DB.Exec(r.Context(),
 			`UPDATE users SET name = $1, email = $2, role = $3, updated_at = $4 WHERE id = $5`,
 			req.Name, req.Email, req.Role, now, id,
 		)
 		if err != nil {
-			http.Error(w, `{"error":"Failed to update user"}`, http.StatusInternalServerError)
+			apierror.Write(w, r, errUpdateUserFailed)
+			return
+		}
+	}
+
+	// Token lama membawa email/role lama, jadi semua sesi user dicabut
+	if req.Password != "" || req.Email != oldEmail || req.Role != oldRole {
+		if err := revokeUserSessions(r.Context(), config.DB, id); err != nil {
+			apierror.Write(w, r, errRevokeSessionsFailed)
 			return
 		}
 	}
 
+	// Perubahan role dan email dicatat dengan nilai lama dan baru
+	changes := map[string]interface{}{}
+	if req.Role != oldRole {
+		changes["role"] = map[string]string{"from": oldRole, "to": req.Role}
+	}
+	if req.Email != oldEmail {
+		changes["email"] = map[string]string{"from": oldEmail, "to": req.Email}
+	}
+	if req.Password != "" {
+		changes["password_changed"] = true
+	}
+	recordAudit(r, auditEvent{Action: auditUserUpdate, TargetType: targetUser, TargetID: id, Details: changes})
+
 	// Ambil data user yang sudah diupdate
 	var u models.UserResponse
-	config.DB.QueryRow(context.Background(),
+	config.DB.QueryRow(r.Context(),
 		`SELECT id, name, email, role, created_at, updated_at FROM users WHERE id = $1`, id).Scan(
 		&u.ID, &u.Name, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt,
 	)
@@ -264,27 +254,32 @@ func updateUser(w http.ResponseWriter, r *http.Request, id string) {
 }
 
 // deleteUser menghapus user
-func deleteUser(w http.ResponseWriter, r *http.Request, id string) {
+// DELETE /api/users/:id
+func deleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
 	// Tidak boleh menghapus diri sendiri
 	currentUserID := r.Header.Get("X-User-ID")
 	if currentUserID == id {
-		http.Error(w, `{"error":"Cannot delete your own account"}`, http.StatusForbidden)
+		apierror.Write(w, r, errCannotDeleteSelf)
 		return
 	}
 
-	result, err := config.DB.Exec(context.Background(),
-		`DELETE FROM users WHERE id = $1`, id)
+	var email string
+	err := config.DB.QueryRow(r.Context(),
+		`DELETE FROM users WHERE id = $1 RETURNING email`, id).Scan(&email)
 
-	if err != nil {
-		http.Error(w, `{"error":"Failed to delete user"}`, http.StatusInternalServerError)
+	if errors.Is(err, pgx.ErrNoRows) {
+		apierror.Write(w, r, errUserNotFound)
 		return
 	}
-
-	if result.RowsAffected() == 0 {
-		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+	if err != nil {
+		apierror.Write(w, r, errDeleteUserFailed)
 		return
 	}
 
+	recordAudit(r, auditEvent{Action: auditUserDelete, TargetType: targetUser, TargetID: id,
+		Details: map[string]interface{}{"email": email}})
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Write([]byte(`{"message":"User deleted successfully"}`))
 }
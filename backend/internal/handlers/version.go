@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+	"repository-un/internal/router"
+	"repository-un/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// listVersions mengambil semua versi file dari dokumen.
+// Versi bisa dilihat oleh siapa saja yang boleh melihat dokumen,
+// restore hanya oleh pemilik atau admin.
+// GET /api/documents/:id/versions
+func listVersions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	rows, err := config.DB.Query(r.Context(),
+		`SELECT `+versionColumns+`
+		 FROM document_versions v JOIN documents d ON d.id = v.document_id
+		 WHERE v.document_id = $1
+		 ORDER BY v.version_number DESC`, id)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+	defer rows.Close()
+
+	versions := []models.DocumentVersion{}
+
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			apierror.Write(w, r, errReadFailed)
+			return
+		}
+		versions = append(versions, v)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// downloadVersion mengirim file dari versi tertentu
+// GET /api/documents/:id/versions/:number/download
+func downloadVersion(w http.ResponseWriter, r *http.Request) {
+	id, number := r.PathValue("id"), router.IntParam(r, "number")
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	v, err := getVersion(r.Context(), id, number)
+	if err != nil {
+		apierror.Write(w, r, errVersionNotFound)
+		return
+	}
+
+	if !checkScanStatus(w, r, v.ScanStatus) {
+		return
+	}
+
+	f, info, err := config.Storage.Open(r.Context(), fileKey(v.FilePath))
+	if err != nil {
+		apierror.Write(w, r, errFileNotFound)
+		return
+	}
+	defer f.Close()
+
+	name := v.OriginalName
+	if name == "" {
+		name = path.Base(info.Key)
+	}
+
+	if isNewDownload(r) {
+		recordAudit(r, auditEvent{Action: auditDownload, TargetType: targetDocument, TargetID: id,
+			Details: map[string]interface{}{"version_number": v.VersionNumber}})
+	}
+
+	setFileContentType(w, v.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, info.ModTime, f)
+}
+
+// listVersionPages mengembalikan list halaman hasil split dari versi tertentu
+// GET /api/documents/:id/versions/:number/pages
+func listVersionPages(w http.ResponseWriter, r *http.Request) {
+	id, number := r.PathValue("id"), router.IntParam(r, "number")
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	v, err := getVersion(r.Context(), id, number)
+	if err != nil {
+		apierror.Write(w, r, errVersionNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listPages(r.Context(), v.SplitDir))
+}
+
+// restoreVersion menjadikan versi lama sebagai versi aktif dokumen.
+// Tidak ada file yang dihapus, versi lain tetap bisa dipulihkan kembali.
+// POST /api/documents/:id/versions/:number/restore
+func restoreVersion(w http.ResponseWriter, r *http.Request) {
+	id, number := r.PathValue("id"), router.IntParam(r, "number")
+	if _, ok := authorizeContentEdit(w, r, id); !ok {
+		return
+	}
+
+	v, err := getVersion(r.Context(), id, number)
+	if err != nil {
+		apierror.Write(w, r, errVersionNotFound)
+		return
+	}
+
+	// Versi aktif dan jadwal pemrosesannya tetap disimpan walaupun client
+	// memutus koneksi
+	ctx := context.WithoutCancel(r.Context())
+
+	// Versi aktif tidak pernah berganti tanpa job pemrosesannya
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		apierror.Write(w, r, errRestoreFailed)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockEditableDocument(ctx, tx, id); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE documents SET file_path = $1, mime_type = $2, current_version_id = $3, updated_at = NOW()
+		 WHERE id = $4`,
+		v.FilePath, v.MimeType, v.ID, id)
+	if err != nil {
+		apierror.Write(w, r, errRestoreFailed)
+		return
+	}
+
+	// Halaman dan index teks versi yang dipulihkan dibuat ulang
+	if err := enqueueProcessing(ctx, tx, id, v.ID); err != nil {
+		apierror.Write(w, r, errRestoreFailed)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errRestoreFailed)
+		return
+	}
+
+	v.IsCurrent = true
+
+	recordAudit(r, auditEvent{Action: auditRestore, TargetType: targetDocument, TargetID: id,
+		Details: map[string]interface{}{"version_number": v.VersionNumber}})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// versionColumns adalah kolom yang dibaca oleh scanVersion.
+// Query harus memberi alias "v" untuk document_versions dan "d" untuk documents.
+const versionColumns = `v.id, v.document_id, v.version_number, v.file_path, v.original_name,
+	v.file_size, v.mime_type, COALESCE(v.sha256, ''), v.scan_status, v.split_dir, v.page_count, v.created_by, v.created_at,
+	d.current_version_id IS NOT DISTINCT FROM v.id`
+
+// scanVersion membaca satu baris versionColumns
+func scanVersion(row pgx.Row) (models.DocumentVersion, error) {
+	var v models.DocumentVersion
+	err := row.Scan(
+		&v.ID,
+		&v.DocumentID,
+		&v.VersionNumber,
+		&v.FilePath,
+		&v.OriginalName,
+		&v.FileSize,
+		&v.MimeType,
+		&v.SHA256,
+		&v.ScanStatus,
+		&v.SplitDir,
+		&v.PageCount,
+		&v.CreatedBy,
+		&v.CreatedAt,
+		&v.IsCurrent,
+	)
+	return v, err
+}
+
+// getVersion mengambil versi dokumen berdasarkan nomor versi
+func getVersion(ctx context.Context, id string, number int) (models.DocumentVersion, error) {
+	return scanVersion(config.DB.QueryRow(ctx,
+		`SELECT `+versionColumns+`
+		 FROM document_versions v JOIN documents d ON d.id = v.document_id
+		 WHERE v.document_id = $1 AND v.version_number = $2`, id, number))
+}
+
+// saveVersion mencatat file sebagai versi terbaru dokumen di dalam tx dan
+// menjadikannya versi aktif. v.ID dan v.SplitDir sudah harus diisi oleh
+// pemanggil. File staging dipindah ke blob v.SHA256, lihat registerBlob.
+func saveVersion(ctx context.Context, tx *fileTx, v *models.DocumentVersion, staging string) error {
+	// Kunci baris dokumen agar nomor versi tidak bentrok
+	var filePath string
+	err := tx.QueryRow(ctx,
+		`SELECT file_path FROM documents WHERE id = $1 FOR UPDATE`, v.DocumentID).Scan(&filePath)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(version_number), 0) + 1 FROM document_versions WHERE document_id = $1`,
+		v.DocumentID).Scan(&v.VersionNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := registerBlob(ctx, tx, v, staging); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO document_versions
+		    (id, document_id, version_number, file_path, original_name, file_size, mime_type,
+		     sha256, split_dir, page_count, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 RETURNING created_at`,
+		v.ID, v.DocumentID, v.VersionNumber, v.FilePath, v.OriginalName,
+		v.FileSize, v.MimeType, v.SHA256, v.SplitDir, v.PageCount, v.CreatedBy,
+	).Scan(&v.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE documents SET file_path = $1, mime_type = $2, current_version_id = $3, updated_at = NOW()
+		 WHERE id = $4`,
+		v.FilePath, v.MimeType, v.ID, v.DocumentID)
+	if err != nil {
+		return err
+	}
+
+	v.IsCurrent = true
+	return nil
+}
+
+// newVersion menyiapkan data versi untuk file yang baru diupload
+func newVersion(r *http.Request, id string, f storedFile) models.DocumentVersion {
+	versionID := uuid.New().String()
+	return models.DocumentVersion{
+		ID:           versionID,
+		DocumentID:   id,
+		FilePath:     f.Path,
+		OriginalName: originalName(f.Name),
+		FileSize:     f.Size,
+		MimeType:     f.MimeType,
+		SHA256:       f.SHA256,
+		ScanStatus:   ScanPending,
+		SplitDir:     versionSplitDir(id, versionID),
+		CreatedBy:    userIDOrNil(r),
+	}
+}
+
+// currentSplitDir mengembalikan lokasi halaman hasil split dari versi aktif
+func currentSplitDir(ctx context.Context, id string) string {
+	var dir string
+	config.DB.QueryRow(ctx,
+		`SELECT v.split_dir
+		 FROM documents d JOIN document_versions v ON v.id = d.current_version_id
+		 WHERE d.id = $1`, id).Scan(&dir)
+
+	if dir == "" {
+		return splitDir(id)
+	}
+	return dir
+}
+
+// listPages mengembalikan nama file halaman hasil split di dir.
+// Hanya file langsung di dir, bukan sub-folder milik versi lain.
+func listPages(ctx context.Context, dir string) []string {
+	pages := []string{}
+	files, _ := config.Storage.List(ctx, dir)
+	for _, f := range files {
+		if path.Dir(f.Key) == dir && strings.HasSuffix(f.Key, ".pdf") {
+			pages = append(pages, path.Base(f.Key))
+		}
+	}
+	return pages
+}
+
+// versionSplitDir mengembalikan lokasi halaman hasil split untuk versi baru
+func versionSplitDir(id, versionID string) string {
+	return storage.Join(splitDir(id), versionID)
+}
+
+// userIDOrNil mengambil ID user yang login dari header, nil jika anonim
+func userIDOrNil(r *http.Request) *string {
+	userID := r.Header.Get("X-User-ID")
+	if userID == "" {
+		return nil
+	}
+	return &userID
+}
+
+// originalName mengambil nama file asli dari upload tanpa path dari client
+func originalName(filename string) string {
+	return filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+}
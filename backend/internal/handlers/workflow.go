@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+	"repository-un/internal/models"
+	"repository-un/internal/workflow"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// actionCreate adalah aksi yang dicatat di riwayat saat dokumen dibuat
+const actionCreate = "create"
+
+// transitionDocument menjalankan transisi status dokumen
+// POST /api/documents/:id/{submit|withdraw|start-review|publish|reject|archive|reopen}
+// Body (opsional): {"comment": "..."}; wajib untuk reject
+func transitionDocument(w http.ResponseWriter, r *http.Request) {
+	id, action := r.PathValue("id"), r.PathValue("action")
+
+	if !requireLogin(w, r) {
+		return
+	}
+
+	var req models.TransitionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, r, apierror.BadRequest)
+			return
+		}
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+
+	v := currentViewer(r)
+	ctx := r.Context()
+
+	tx, err := config.DB.Begin(ctx)
+	if err != nil {
+		apierror.Write(w, r, errStatusChangeFailed)
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris dokumen agar dua transisi tidak berjalan bersamaan
+	doc := documentAccess{ID: id}
+	err = tx.QueryRow(ctx,
+		`SELECT owner_id, status FROM documents WHERE id = $1 FOR UPDATE`, id).Scan(&doc.OwnerID, &doc.Status)
+	if err != nil || !v.canView(doc) {
+		apierror.Write(w, r, errDocumentNotFound)
+		return
+	}
+
+	isOwner := doc.OwnerID != nil && *doc.OwnerID == v.UserID
+	t, err := workflow.Resolve(action, doc.Status, isOwner, v.isAdmin(), req.Comment)
+
+	var invalid *workflow.InvalidTransitionError
+	switch {
+	case errors.Is(err, workflow.ErrForbidden):
+		apierror.Write(w, r, errTransitionForbidden)
+		return
+	case errors.Is(err, workflow.ErrCommentRequired):
+		apierror.Write(w, r, apierror.ValidationFailed.WithFields(apierror.Field("comment", "required",
+			"wajib diisi untuk aksi ini", "is required for this action")))
+		return
+	case errors.As(err, &invalid):
+		apierror.Write(w, r, errInvalidTransition.WithMessage(apierror.Textf(
+			"aksi %q tidak dapat dilakukan pada dokumen berstatus %q",
+			"action %q cannot be performed on a document with status %q", invalid.Action, invalid.From)))
+		return
+	case err != nil:
+		apierror.Write(w, r, apierror.NotFound)
+		return
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE documents SET status = $1, updated_at = NOW() WHERE id = $2`, t.To, id)
+	if err != nil {
+		apierror.Write(w, r, errStatusChangeFailed)
+		return
+	}
+
+	history, err := recordStatusChange(ctx, tx, id, action, &doc.Status, t.To, v.UserID, req.Comment)
+	if err != nil {
+		apierror.Write(w, r, errHistoryFailed)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		apierror.Write(w, r, errStatusChangeFailed)
+		return
+	}
+
+	recordAudit(r, auditEvent{Action: auditTransition, TargetType: targetDocument, TargetID: id,
+		Details: map[string]interface{}{"action": action, "from": doc.Status, "to": t.To, "comment": req.Comment}})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.TransitionResponse{
+		ID:               id,
+		Status:           t.To,
+		History:          history,
+		AvailableActions: workflow.Available(t.To, isOwner, v.isAdmin()),
+	})
+}
+
+// listStatusHistory mengembalikan riwayat perpindahan status dokumen
+// GET /api/documents/:id/history
+func listStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := authorizeView(w, r, id); !ok {
+		return
+	}
+
+	rows, err := config.DB.Query(r.Context(),
+		`SELECT h.id, h.document_id, h.action, h.from_status, h.to_status,
+		        h.actor_id, u.name, h.comment, h.created_at
+		 FROM document_status_history h
+		 LEFT JOIN users u ON u.id = h.actor_id
+		 WHERE h.document_id = $1
+		 ORDER BY h.created_at ASC`, id)
+	if err != nil {
+		apierror.Write(w, r, errFetchFailed)
+		return
+	}
+	defer rows.Close()
+
+	history := []models.StatusHistory{}
+
+	for rows.Next() {
+		var h models.StatusHistory
+		err := rows.Scan(&h.ID, &h.DocumentID, &h.Action, &h.FromStatus, &h.ToStatus,
+			&h.ActorID, &h.ActorName, &h.Comment, &h.CreatedAt)
+		if err != nil {
+			apierror.Write(w, r, errReadFailed)
+			return
+		}
+		history = append(history, h)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// dbExecutor adalah bagian dari pgxpool.Pool / pgx.Tx yang dipakai
+// fungsi yang bisa berjalan di dalam maupun di luar transaksi
+type dbExecutor interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// recordStatusChange menyimpan satu baris riwayat status
+func recordStatusChange(ctx context.Context, db dbExecutor, id, action string, from *string, to, actorID, comment string) (models.StatusHistory, error) {
+	h := models.StatusHistory{
+		ID:         uuid.New().String(),
+		DocumentID: id,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   to,
+		Comment:    comment,
+	}
+	if actorID != "" {
+		h.ActorID = &actorID
+	}
+
+	err := db.QueryRow(ctx,
+		`INSERT INTO document_status_history (id, document_id, action, from_status, to_status, actor_id, comment)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING created_at`,
+		h.ID, h.DocumentID, h.Action, h.FromStatus, h.ToStatus, h.ActorID, h.Comment,
+	).Scan(&h.CreatedAt)
+
+	return h, err
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Status job di tabel jobs
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// DefaultMaxAttempts adalah jumlah percobaan maksimal sebelum job dianggap gagal
+const DefaultMaxAttempts = 5
+
+// Job adalah satu pekerjaan yang diambil worker dari tabel jobs
+type Job struct {
+	ID          string
+	Type        string
+	Payload     json.RawMessage
+	Attempts    int
+	MaxAttempts int
+
+	// lockedAt adalah nilai locked_at saat job diklaim (atau diperbarui
+	// heartbeat), dipakai untuk memastikan worker masih memegang klaim
+	lockedAt time.Time
+}
+
+// LastAttempt menandakan percobaan sekarang adalah yang terakhir,
+// jika gagal job tidak akan diulang lagi
+func (j Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// Decode membaca payload job ke v
+func (j Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Handler menjalankan satu job. Error biasa membuat job diulang dengan
+// backoff, error dari Permanent membuat job langsung gagal.
+type Handler func(ctx context.Context, job Job) error
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = map[string]Handler{}
+)
+
+// Register mendaftarkan handler untuk tipe job
+func Register(jobType string, h Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = h
+}
+
+func handlerFor(jobType string) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := handlers[jobType]
+	return h, ok
+}
+
+// permanentError menandai error yang tidak akan hilang jika job diulang
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent membungkus err agar job tidak dicoba ulang
+// (misalnya file rusak)
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent mengecek apakah err dibuat dengan Permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Executor adalah bagian dari pgxpool.Pool / pgx.Tx yang dipakai Enqueue,
+// sehingga job bisa dibuat di dalam transaksi yang sama dengan datanya
+type Executor interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// Enqueue memasukkan job baru ke antrian dan mengembalikan ID-nya
+func Enqueue(ctx context.Context, db Executor, jobType string, payload interface{}) (string, error) {
+	return enqueue(ctx, db, jobType, payload, nil)
+}
+
+// EnqueueAt sama dengan Enqueue, tetapi job baru dijalankan mulai runAt
+// (misalnya pembersihan sesi yang kedaluwarsa)
+func EnqueueAt(ctx context.Context, db Executor, jobType string, payload interface{}, runAt time.Time) (string, error) {
+	return enqueue(ctx, db, jobType, payload, &runAt)
+}
+
+// enqueue menyimpan job, runAt nil berarti dijalankan secepatnya
+func enqueue(ctx context.Context, db Executor, jobType string, payload interface{}, runAt *time.Time) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New().String()
+	_, err = db.Exec(ctx,
+		`INSERT INTO jobs (id, type, payload, max_attempts, run_at)
+		 VALUES ($1, $2, $3, $4, COALESCE($5, CURRENT_TIMESTAMP))`,
+		id, jobType, data, DefaultMaxAttempts, runAt)
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat job %s: %w", jobType, err)
+	}
+	return id, nil
+}
+
+// Backoff mengembalikan jeda sebelum percobaan berikutnya:
+// 10 detik, 20 detik, 40 detik, ... maksimal 10 menit
+func Backoff(attempts int) time.Duration {
+	const (
+		base = 10 * time.Second
+		max  = 10 * time.Minute
+	)
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 10 {
+		return max
+	}
+	d := base << (attempts - 1)
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// scanJob membaca satu baris job hasil claim
+func scanJob(row pgx.Row) (Job, error) {
+	var j Job
+	err := row.Scan(&j.ID, &j.Type, &j.Payload, &j.Attempts, &j.MaxAttempts, &j.lockedAt)
+	return j, err
+}
@@ -0,0 +1,276 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Worker mengambil job dari tabel jobs dan menjalankannya di background.
+// Beberapa instance server boleh berjalan bersamaan karena job diklaim
+// dengan FOR UPDATE SKIP LOCKED.
+type Worker struct {
+	DB *pgxpool.Pool
+
+	// Concurrency adalah jumlah job yang dijalankan bersamaan
+	Concurrency int
+
+	// PollInterval adalah jeda pengecekan antrian saat kosong
+	PollInterval time.Duration
+
+	// StaleAfter adalah batas waktu job berstatus running tanpa heartbeat,
+	// setelah itu dianggap ditinggal (server mati) dan diantrikan ulang.
+	// Selama job berjalan, locked_at diperbarui setiap StaleAfter/3.
+	StaleAfter time.Duration
+
+	// RetainDone adalah lama job yang selesai disimpan sebelum dihapus
+	RetainDone time.Duration
+
+	running context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewWorker membuat worker dengan pengaturan default
+func NewWorker(db *pgxpool.Pool, concurrency int) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		DB:           db,
+		Concurrency:  concurrency,
+		PollInterval: 2 * time.Second,
+		StaleAfter:   15 * time.Minute,
+		RetainDone:   7 * 24 * time.Hour,
+	}
+}
+
+// Start menjalankan worker di background sampai Stop dipanggil atau ctx selesai
+func (w *Worker) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.running = ctx
+
+	for i := 0; i < w.Concurrency; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.maintain(ctx)
+	}()
+
+	slog.Info("✅ Job worker started", "concurrency", w.Concurrency)
+}
+
+// Stop menghentikan pengambilan job baru dan menunggu job yang sedang
+// berjalan selesai
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Check memastikan worker berjalan dan antrian tidak macet: job yang
+// sudah waktunya dijalankan tidak boleh menunggu lebih lama dari StaleAfter
+func (w *Worker) Check(ctx context.Context) error {
+	if w.running == nil || w.running.Err() != nil {
+		return errors.New("worker job tidak berjalan")
+	}
+
+	var waiting float64
+	err := w.DB.QueryRow(ctx,
+		`SELECT COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(run_at)), 0)
+		 FROM jobs WHERE status = $1 AND run_at <= NOW()`, StatusQueued).Scan(&waiting)
+	if err != nil {
+		return err
+	}
+
+	if wait := time.Duration(waiting * float64(time.Second)); wait > w.StaleAfter {
+		return fmt.Errorf("job tertua sudah menunggu %s", wait.Round(time.Second))
+	}
+	return nil
+}
+
+// loop mengambil dan menjalankan job satu per satu
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		job, err := w.claim(ctx)
+		switch {
+		case err == nil:
+			w.run(ctx, job)
+			continue
+		case errors.Is(err, pgx.ErrNoRows):
+			// Antrian kosong
+		case ctx.Err() == nil:
+			slog.Error("Gagal mengambil job", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.PollInterval):
+		}
+	}
+}
+
+// claim mengambil satu job yang sudah waktunya dijalankan
+func (w *Worker) claim(ctx context.Context) (Job, error) {
+	return scanJob(w.DB.QueryRow(ctx,
+		`UPDATE jobs
+		 SET status = $1, attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		 WHERE id = (
+		     SELECT id FROM jobs
+		     WHERE status = $2 AND run_at <= NOW()
+		     ORDER BY run_at
+		     FOR UPDATE SKIP LOCKED
+		     LIMIT 1
+		 )
+		 RETURNING id, type, payload, attempts, max_attempts, locked_at`,
+		StatusRunning, StatusQueued))
+}
+
+// run menjalankan handler job lalu mencatat hasilnya. Job yang sedang
+// berjalan tetap diselesaikan walaupun worker dihentikan. Hasil hanya
+// dicatat jika worker masih memegang klaim job; jika job sudah diantrikan
+// ulang dan diklaim worker lain, hasil dari worker ini dibuang.
+func (w *Worker) run(ctx context.Context, job Job) {
+	ctx = context.WithoutCancel(ctx)
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	stop := make(chan struct{})
+	lockedAt := make(chan time.Time, 1)
+	go func() {
+		lockedAt <- w.heartbeat(jobCtx, cancel, job, stop)
+	}()
+	err := w.execute(jobCtx, job)
+	close(stop)
+	job.lockedAt = <-lockedAt
+	cancel()
+
+	var tag pgconn.CommandTag
+	if err == nil {
+		tag, err = w.DB.Exec(ctx,
+			`UPDATE jobs SET status = $1, last_error = NULL, locked_at = NULL, updated_at = NOW()
+			 WHERE id = $2 AND status = $3 AND locked_at = $4`,
+			StatusDone, job.ID, StatusRunning, job.lockedAt)
+		if err != nil {
+			slog.Error("Gagal menandai job selesai", "job_id", job.ID, "error", err)
+		} else if tag.RowsAffected() == 0 {
+			slog.Warn("Klaim job sudah hilang, hasil tidak dicatat", "job_id", job.ID, "type", job.Type)
+		}
+		return
+	}
+
+	if IsPermanent(err) || job.LastAttempt() {
+		slog.Error("Job gagal", "job_id", job.ID, "type", job.Type, "error", err)
+		tag, err = w.DB.Exec(ctx,
+			`UPDATE jobs SET status = $1, last_error = $2, locked_at = NULL, updated_at = NOW()
+			 WHERE id = $3 AND status = $4 AND locked_at = $5`,
+			StatusFailed, err.Error(), job.ID, StatusRunning, job.lockedAt)
+	} else {
+		delay := Backoff(job.Attempts)
+		slog.Warn("Job gagal, dicoba lagi", "job_id", job.ID, "type", job.Type, "retry_in", delay, "error", err)
+		tag, err = w.DB.Exec(ctx,
+			`UPDATE jobs SET status = $1, last_error = $2, locked_at = NULL, updated_at = NOW(),
+			        run_at = NOW() + $3 * INTERVAL '1 second'
+			 WHERE id = $4 AND status = $5 AND locked_at = $6`,
+			StatusQueued, err.Error(), delay.Seconds(), job.ID, StatusRunning, job.lockedAt)
+	}
+	if err != nil {
+		slog.Error("Gagal mencatat hasil job", "job_id", job.ID, "error", err)
+	} else if tag.RowsAffected() == 0 {
+		slog.Warn("Klaim job sudah hilang, hasil tidak dicatat", "job_id", job.ID, "type", job.Type)
+	}
+}
+
+// heartbeat memperbarui locked_at setiap StaleAfter/3 sampai stop ditutup,
+// sehingga job yang lama tetapi masih berjalan tidak diantrikan ulang oleh
+// maintain. Jika klaim sudah hilang, cancel dipanggil agar handler berhenti.
+// Nilai locked_at terakhir dikembalikan untuk mencatat hasil job.
+func (w *Worker) heartbeat(ctx context.Context, cancel context.CancelFunc, job Job, stop <-chan struct{}) time.Time {
+	ticker := time.NewTicker(w.StaleAfter / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return job.lockedAt
+		case <-ticker.C:
+		}
+
+		err := w.DB.QueryRow(ctx,
+			`UPDATE jobs SET locked_at = NOW(), updated_at = NOW()
+			 WHERE id = $1 AND status = $2 AND locked_at = $3
+			 RETURNING locked_at`,
+			job.ID, StatusRunning, job.lockedAt).Scan(&job.lockedAt)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			slog.Warn("Klaim job hilang, job dihentikan", "job_id", job.ID, "type", job.Type)
+			cancel()
+			<-stop
+			return job.lockedAt
+		case err != nil && ctx.Err() == nil:
+			// Dicoba lagi pada tick berikutnya, masih ada waktu sebelum
+			// StaleAfter terlewati
+			slog.Error("Gagal memperbarui heartbeat job", "job_id", job.ID, "error", err)
+		}
+	}
+}
+
+// execute memanggil handler job dan mengubah panic menjadi error
+func (w *Worker) execute(ctx context.Context, job Job) (err error) {
+	h, ok := handlerFor(job.Type)
+	if !ok {
+		return Permanent(fmt.Errorf("tipe job tidak dikenal: %s", job.Type))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return h(ctx, job)
+}
+
+// maintain mengantrikan ulang job yang ditinggal dan menghapus job lama
+func (w *Worker) maintain(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.DB.Exec(ctx,
+			`UPDATE jobs SET status = $1, locked_at = NULL, updated_at = NOW()
+			 WHERE status = $2 AND locked_at < NOW() - $3 * INTERVAL '1 second'`,
+			StatusQueued, StatusRunning, w.StaleAfter.Seconds())
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Gagal mengantrikan ulang job", "error", err)
+		}
+
+		_, err = w.DB.Exec(ctx,
+			`DELETE FROM jobs WHERE status = $1 AND updated_at < NOW() - $2 * INTERVAL '1 second'`,
+			StatusDone, w.RetainDone.Seconds())
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Gagal menghapus job lama", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma tanda tangan yang didukung
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// minRSABits adalah ukuran kunci RSA paling kecil yang diterima
+const minRSABits = 2048
+
+// Key adalah satu kunci JWT. Kunci verifikasi saja (hasil rotasi) tidak
+// punya signing key.
+type Key struct {
+	// ID dikirim sebagai header "kid" pada token
+	ID string
+
+	// Algorithm adalah HS256, RS256 atau EdDSA
+	Algorithm string
+
+	// signing adalah []byte (HS256), *rsa.PrivateKey atau ed25519.PrivateKey
+	signing interface{}
+
+	// verify adalah []byte (HS256), *rsa.PublicKey atau ed25519.PublicKey
+	verify interface{}
+}
+
+// CanSign menunjukkan apakah kunci bisa dipakai untuk membuat token
+func (k *Key) CanSign() bool {
+	return k.signing != nil
+}
+
+// method mengembalikan jwt.SigningMethod sesuai algoritma kunci
+func (k *Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case RS256:
+		return jwt.SigningMethodRS256
+	case EdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// NewSecretKey membuat kunci HS256 dari secret. id kosong berarti
+// diturunkan dari hash secret.
+func NewSecretKey(id string, secret []byte) (*Key, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("secret HS256 minimal 32 byte")
+	}
+	if id == "" {
+		sum := sha256.Sum256(secret)
+		id = hex.EncodeToString(sum[:8])
+	}
+	return &Key{ID: id, Algorithm: HS256, signing: secret, verify: secret}, nil
+}
+
+// ParsePEM membaca kunci RSA atau Ed25519 dari PEM. Private key
+// (PKCS#1/PKCS#8) menghasilkan kunci yang bisa sign; public key
+// (PKIX/PKCS#1) hanya untuk verifikasi. id kosong berarti diturunkan
+// dari hash public key.
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("bukan file PEM")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipe PEM tidak didukung: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Key{ID: id}
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.Algorithm, k.signing, k.verify = RS256, key, &key.PublicKey
+	case *rsa.PublicKey:
+		k.Algorithm, k.verify = RS256, key
+	case ed25519.PrivateKey:
+		k.Algorithm, k.signing, k.verify = EdDSA, key, key.Public()
+	case ed25519.PublicKey:
+		k.Algorithm, k.verify = EdDSA, key
+	default:
+		return nil, fmt.Errorf("tipe kunci tidak didukung: %T", parsed)
+	}
+
+	if pub, ok := k.verify.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("kunci RSA minimal %d bit", minRSABits)
+	}
+
+	if k.ID == "" {
+		k.ID, err = thumbprint(k.verify)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// LoadPEMFile membaca kunci dari file PEM (lihat ParsePEM)
+func LoadPEMFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := ParsePEM(id, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// thumbprint menghasilkan kid dari hash SHA-256 public key (DER)
+func thumbprint(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
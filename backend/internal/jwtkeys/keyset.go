@@ -0,0 +1,133 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKey dikembalikan jika kid token tidak ada di key set
+var ErrUnknownKey = errors.New("kid token tidak dikenal")
+
+// KeySet berisi satu kunci untuk membuat token dan semua kunci yang masih
+// diterima untuk verifikasi. Saat rotasi, kunci lama tetap dipasang sebagai
+// kunci verifikasi sampai token terakhir yang ditandatanganinya kedaluwarsa.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+
+	// ordered berisi semua kunci, signing key di urutan pertama
+	ordered []*Key
+}
+
+// NewKeySet membuat key set dengan signing sebagai kunci penandatangan.
+// verification adalah kunci tambahan yang hanya dipakai untuk verifikasi.
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("signing key wajib berupa private key atau secret")
+	}
+
+	s := &KeySet{
+		signing: signing,
+		keys:    map[string]*Key{signing.ID: signing},
+		ordered: []*Key{signing},
+	}
+	for _, k := range verification {
+		if _, exists := s.keys[k.ID]; exists {
+			return nil, fmt.Errorf("kid duplikat: %s", k.ID)
+		}
+		s.keys[k.ID] = k
+		s.ordered = append(s.ordered, k)
+	}
+	return s, nil
+}
+
+// SigningKey mengembalikan kunci yang dipakai untuk membuat token
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// Sign membuat token bertanda tangan dengan header kid
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.method(), claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.signing)
+}
+
+// Keyfunc mencari kunci verifikasi berdasarkan header kid, dipakai
+// sebagai jwt.Keyfunc. Algoritma token harus sama dengan algoritma kunci.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != k.Algorithm {
+		return nil, fmt.Errorf("algoritma token %s tidak sesuai dengan kunci %s", token.Method.Alg(), kid)
+	}
+	return k.verify, nil
+}
+
+// Methods mengembalikan algoritma yang diterima saat verifikasi
+func (s *KeySet) Methods() []string {
+	seen := map[string]bool{}
+	var methods []string
+	for _, k := range s.ordered {
+		if !seen[k.Algorithm] {
+			seen[k.Algorithm] = true
+			methods = append(methods, k.Algorithm)
+		}
+	}
+	return methods
+}
+
+// JWK adalah satu public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519 (OKP)
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS adalah dokumen /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan semua public key di key set. Kunci HS256 tidak
+// pernah dipublikasikan karena merupakan secret.
+func (s *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+
+	for _, k := range s.ordered {
+		jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+		switch pub := k.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
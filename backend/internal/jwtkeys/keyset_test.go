@@ -0,0 +1,156 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// parse memverifikasi token seperti AuthMiddleware
+func parse(s *KeySet, token string) error {
+	_, err := jwt.Parse(token, s.Keyfunc, jwt.WithValidMethods(s.Methods()))
+	return err
+}
+
+func secretKey(t *testing.T, id, secret string) *Key {
+	t.Helper()
+	k, err := NewSecretKey(id, []byte(secret))
+	if err != nil {
+		t.Fatalf("NewSecretKey: %v", err)
+	}
+	return k
+}
+
+func ed25519Key(t *testing.T, id string) *Key {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := ParsePEM(id, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePEM: %v", err)
+	}
+	return k
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey := secretKey(t, "2025-01", strings.Repeat("a", 32))
+	newKey := ed25519Key(t, "2025-06")
+
+	before, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setelah rotasi, kunci lama hanya dipakai untuk verifikasi
+	after, err := NewKeySet(newKey, secretKey(t, "2025-01", strings.Repeat("a", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := after.Sign(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parse(after, oldToken); err != nil {
+		t.Errorf("token lama ditolak setelah rotasi: %v", err)
+	}
+	if err := parse(after, newToken); err != nil {
+		t.Errorf("token baru ditolak: %v", err)
+	}
+	if err := parse(before, newToken); err == nil {
+		t.Error("key set lama menerima token dari kunci baru")
+	}
+
+	tok, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if kid := tok.Header["kid"]; kid != "2025-06" {
+		t.Errorf("kid = %v, want 2025-06", kid)
+	}
+	if got := after.Methods(); len(got) != 2 || got[0] != EdDSA || got[1] != HS256 {
+		t.Errorf("Methods = %v", got)
+	}
+}
+
+func TestKeySetUnknownKid(t *testing.T) {
+	secret := strings.Repeat("s", 32)
+	keys, err := NewKeySet(secretKey(t, "current", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, kid := range []interface{}{"lain", "", nil, 42} {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+		token.Header["kid"] = kid
+		signed, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parse(keys, signed); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("kid %v: error = %v, want ErrUnknownKey", kid, err)
+		}
+	}
+}
+
+func TestKeySetAlgorithmMismatch(t *testing.T) {
+	edKey := ed25519Key(t, "ed")
+	keys, err := NewKeySet(edKey, secretKey(t, "hs", strings.Repeat("h", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// HS256 dengan kid kunci EdDSA, ditandatangani memakai public key
+	// sebagai secret (algorithm confusion)
+	pub := edKey.verify.(ed25519.PublicKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	token.Header["kid"] = "ed"
+	signed, err := token.SignedString([]byte(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parse(keys, signed); err == nil || !strings.Contains(err.Error(), "tidak sesuai") {
+		t.Errorf("error = %v, want algoritma tidak sesuai", err)
+	}
+
+	// alg none tidak pernah diterima
+	token = jwt.NewWithClaims(jwt.SigningMethodNone, claims())
+	token.Header["kid"] = "hs"
+	signed, err = token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parse(keys, signed); err == nil {
+		t.Error("token alg none diterima")
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	signing := secretKey(t, "a", strings.Repeat("a", 32))
+	if _, err := NewKeySet(signing, secretKey(t, "a", strings.Repeat("b", 32))); err == nil {
+		t.Error("kid duplikat diterima")
+	}
+
+	verifyOnly := &Key{ID: "pub", Algorithm: EdDSA, verify: ed25519.PublicKey(make([]byte, ed25519.PublicKeySize))}
+	if _, err := NewKeySet(verifyOnly); err == nil {
+		t.Error("kunci tanpa private key diterima sebagai signing key")
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry berisi semua metric server, dibaca lewat GET /metrics
+var Registry = prometheus.NewRegistry()
+
+// Metric HTTP. Label route berisi pola route (misalnya
+// "/api/documents/{id}"), bukan path asli, agar jumlah label tetap kecil.
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Jumlah request HTTP per method, route dan status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Durasi request HTTP per method dan route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// UploadSize adalah ukuran file yang diupload, 64KB sampai 4GB
+var UploadSize = prometheus.NewHistogram(prometheus.HistogramOpts{
+	Name:    "upload_size_bytes",
+	Help:    "Ukuran file dokumen yang diupload.",
+	Buckets: prometheus.ExponentialBuckets(64*1024, 4, 9),
+})
+
+// Metric pemrosesan PDF di background. Label result berisi "ready",
+// "retry" (akan dicoba lagi) atau "failed" (gagal permanen).
+var (
+	ProcessingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pdf_processing_duration_seconds",
+		Help:    "Durasi pemrosesan PDF (validasi, split, render, index) per hasil.",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+	}, []string{"result"})
+
+	ProcessingFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pdf_processing_failures_total",
+		Help: "Jumlah pemrosesan PDF yang gagal per hasil (retry atau failed).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		UploadSize,
+		ProcessingDuration,
+		ProcessingFailures,
+	)
+}
+
+// Handler menampilkan semua metric dalam format Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
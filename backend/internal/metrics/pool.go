@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector membaca statistik pool koneksi database saat /metrics diminta
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	newConns         *prometheus.Desc
+	destroyedMaxIdle *prometheus.Desc
+}
+
+// RegisterDBPool menambahkan statistik pool ke metric db_pool_*
+func RegisterDBPool(pool *pgxpool.Pool) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+name, help, nil, nil)
+	}
+
+	Registry.MustRegister(&poolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_conns", "Koneksi yang sedang dipakai."),
+		idleConns:        desc("idle_conns", "Koneksi yang menganggur."),
+		totalConns:       desc("total_conns", "Jumlah koneksi di pool."),
+		maxConns:         desc("max_conns", "Batas jumlah koneksi pool."),
+		acquireCount:     desc("acquire_total", "Jumlah koneksi yang berhasil diambil dari pool."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Total waktu menunggu koneksi dari pool."),
+		emptyAcquire:     desc("empty_acquire_total", "Jumlah pengambilan koneksi yang harus menunggu karena pool kosong."),
+		canceledAcquire:  desc("canceled_acquire_total", "Jumlah pengambilan koneksi yang dibatalkan."),
+		newConns:         desc("new_conns_total", "Jumlah koneksi baru yang dibuka."),
+		destroyedMaxIdle: desc("max_idle_destroy_total", "Jumlah koneksi yang ditutup karena terlalu lama menganggur."),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(c.acquiredConns, float64(s.AcquiredConns()))
+	gauge(c.idleConns, float64(s.IdleConns()))
+	gauge(c.totalConns, float64(s.TotalConns()))
+	gauge(c.maxConns, float64(s.MaxConns()))
+	counter(c.acquireCount, float64(s.AcquireCount()))
+	counter(c.acquireDuration, s.AcquireDuration().Seconds())
+	counter(c.emptyAcquire, float64(s.EmptyAcquireCount()))
+	counter(c.canceledAcquire, float64(s.CanceledAcquireCount()))
+	counter(c.newConns, float64(s.NewConnsCount()))
+	counter(c.destroyedMaxIdle, float64(s.MaxIdleDestroyCount()))
+}
@@ -1,65 +1,128 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
 	"time"
 
+	"repository-un/internal/apierror"
+	"repository-un/internal/config"
+
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
-// JWTSecret adalah kunci rahasia untuk sign JWT token
-// PENTING: Ganti dengan secret yang aman di production!
-var JWTSecret = []byte("your-super-secret-key-change-in-production")
+// AccessTokenTTL adalah masa berlaku access token. Dibuat singkat karena
+// sesi diperpanjang lewat refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
+// ErrTokenRevoked dikembalikan jika token sudah dicabut (logout, user
+// dihapus, atau role/email/password user berubah)
+var ErrTokenRevoked = errors.New("token revoked")
 
 // Claims adalah struktur data yang disimpan dalam JWT token
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+
+	// TokenVersion harus sama dengan users.token_version agar token berlaku
+	TokenVersion int `json:"ver"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken membuat JWT token baru untuk user
-func GenerateToken(userID, email, role string) (string, error) {
-	// Token berlaku selama 24 jam
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateToken membuat JWT access token baru untuk user. tokenVersion
+// adalah nilai users.token_version saat token dibuat.
+func GenerateToken(userID, email, role string, tokenVersion int) (string, error) {
+	now := time.Now()
 
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "repository-un",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JWTSecret)
+	return config.JWTKeys.Sign(claims)
 }
 
-// ValidateToken memvalidasi JWT token dan mengembalikan claims
-func ValidateToken(tokenString string) (*Claims, error) {
+// ValidateToken memvalidasi JWT token dan mengembalikan claims.
+// Token juga ditolak jika sudah dicabut atau versi token user sudah berubah.
+func ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkRevocation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// ParseToken memvalidasi tanda tangan dan masa berlaku JWT token tanpa
+// mengecek daftar pencabutan
+func ParseToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return JWTSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, config.JWTKeys.Keyfunc,
+		jwt.WithValidMethods(config.JWTKeys.Methods()))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
+	if !token.Valid || claims.ID == "" {
 		return nil, errors.New("invalid token")
 	}
 
 	return claims, nil
 }
 
+// checkRevocation memastikan user masih ada, versi token masih sama dan
+// jti token tidak ada di tabel revoked_tokens
+func checkRevocation(ctx context.Context, claims *Claims) error {
+	var version int
+	var revoked bool
+	err := config.DB.QueryRow(ctx,
+		`SELECT u.token_version, EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $2)
+		 FROM users u WHERE u.id = $1`, claims.UserID, claims.ID).Scan(&version, &revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrTokenRevoked
+	}
+	if err != nil {
+		return err
+	}
+
+	if revoked || version != claims.TokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeToken mencabut access token sampai masa berlakunya habis
+func RevokeToken(ctx context.Context, claims *Claims) error {
+	expiresAt := time.Now().Add(AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	_, err := config.DB.Exec(ctx,
+		`INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2)
+		 ON CONFLICT (jti) DO NOTHING`, claims.ID, expiresAt)
+	return err
+}
+
 // GetTokenFromHeader mengambil token dari header Authorization
 // Format: "Bearer <token>"
 func GetTokenFromHeader(r *http.Request) string {
@@ -80,27 +143,20 @@ func GetTokenFromHeader(r *http.Request) string {
 // Middleware ini akan mengecek apakah request memiliki token yang valid
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Handle preflight OPTIONS request
-		if r.Method == http.MethodOptions {
-			EnableCORS(w)
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-		EnableCORS(w)
-
 		// Ambil token dari header
 		token := GetTokenFromHeader(r)
 		if token == "" {
-			http.Error(w, `{"error":"Unauthorized - No token provided"}`, http.StatusUnauthorized)
+			apierror.Write(w, r, apierror.Unauthorized)
 			return
 		}
 
 		// Validasi token
-		claims, err := ValidateToken(token)
+		claims, err := ValidateToken(r.Context(), token)
 		if err != nil {
-			http.Error(w, `{"error":"Unauthorized - Invalid token"}`, http.StatusUnauthorized)
+			apierror.Write(w, r, apierror.InvalidToken)
 			return
 		}
+		setLogUser(r, claims.UserID)
 
 		// Simpan informasi user ke header untuk digunakan handler
 		r.Header.Set("X-User-ID", claims.UserID)
@@ -111,12 +167,45 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware dipakai untuk route yang bisa diakses tanpa login,
+// tetapi hasilnya berbeda untuk user yang login (misalnya dokumen draft).
+// Jika token ada maka harus valid; jika tidak ada, request diteruskan
+// sebagai anonim.
+func OptionalAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Header identitas hanya boleh berasal dari middleware, bukan dari client
+		r.Header.Del("X-User-ID")
+		r.Header.Del("X-User-Email")
+		r.Header.Del("X-User-Role")
+
+		token := GetTokenFromHeader(r)
+		if token != "" {
+			claims, err := ValidateToken(r.Context(), token)
+			if err != nil {
+				apierror.Write(w, r, apierror.InvalidToken)
+				return
+			}
+			setLogUser(r, claims.UserID)
+
+			r.Header.Set("X-User-ID", claims.UserID)
+			r.Header.Set("X-User-Email", claims.Email)
+			r.Header.Set("X-User-Role", claims.Role)
+		}
+
+		next(w, r)
+	}
+}
+
+// errAdminRequired dikembalikan jika route admin diakses user biasa
+var errAdminRequired = apierror.New(http.StatusForbidden, "admin_required",
+	"Hanya admin yang boleh mengakses", "Admin access required")
+
 // AdminMiddleware melindungi route yang hanya boleh diakses admin
 func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		role := r.Header.Get("X-User-Role")
 		if role != "admin" {
-			http.Error(w, `{"error":"Forbidden - Admin access required"}`, http.StatusForbidden)
+			apierror.Write(w, r, errAdminRequired)
 			return
 		}
 		next(w, r)
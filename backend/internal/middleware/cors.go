@@ -1,19 +1,50 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+
+	"repository-un/internal/config"
+)
 
 // EnableCORS menambahkan header CORS ke response
-// CORS diperlukan agar frontend dapat mengakses API dari domain yang berbeda
-func EnableCORS(w http.ResponseWriter) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+// CORS diperlukan agar frontend dapat mengakses API dari domain yang berbeda.
+// Origin yang diizinkan diatur lewat config.App.CORS.AllowedOrigins.
+func EnableCORS(w http.ResponseWriter, r *http.Request) {
+	origin := allowedOrigin(r.Header.Get("Origin"))
+	if origin != "*" {
+		// Response berbeda per origin, jangan di-cache untuk origin lain
+		w.Header().Add("Vary", "Origin")
+	}
+	if origin == "" {
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Chunk-SHA256")
+	w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
+}
+
+// allowedOrigin mengembalikan nilai Access-Control-Allow-Origin untuk
+// origin request, atau "" jika origin tidak diizinkan
+func allowedOrigin(origin string) string {
+	for _, allowed := range config.App.CORS.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && allowed == origin {
+			return origin
+		}
+	}
+	return ""
 }
 
-// CORSMiddleware adalah middleware untuk handle CORS pada semua request
+// CORSMiddleware adalah middleware untuk handle CORS pada semua request.
+// Dipasang sekali di router sehingga preflight OPTIONS dijawab untuk
+// semua route, termasuk route yang butuh login.
 func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		EnableCORS(w)
+		EnableCORS(w, r)
 
 		// Handle preflight request
 		if r.Method == http.MethodOptions {
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// accessLogKey adalah key context untuk data access log yang diisi
+// middleware lain selama request berjalan
+type accessLogKey struct{}
+
+// accessLog berisi data request yang baru diketahui setelah middleware
+// autentikasi berjalan
+type accessLog struct {
+	userID string
+}
+
+// setLogUser mencatat user yang login untuk access log request ini
+func setLogUser(r *http.Request, userID string) {
+	if entry, ok := r.Context().Value(accessLogKey{}).(*accessLog); ok {
+		entry.userID = userID
+	}
+}
+
+// probePaths adalah endpoint yang dipanggil berkala oleh load balancer dan
+// Prometheus. Request yang berhasil ke endpoint ini dicatat pada level debug.
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// AccessLogMiddleware mencatat setiap request setelah selesai: method,
+// path, status, durasi, jumlah byte response dan user yang login.
+// Response 5xx dicatat sebagai error, 4xx sebagai warning.
+func AccessLogMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessLog{}
+		rec := &responseRecorder{ResponseWriter: w}
+
+		next(rec, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		case probePaths[r.URL.Path]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int64("bytes", rec.bytes),
+			slog.String("remote_addr", r.RemoteAddr),
+		}
+		if entry.userID != "" {
+			attrs = append(attrs, slog.String("user_id", entry.userID))
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
+	}
+}
+
+// responseRecorder mencatat status dan jumlah byte response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap dipakai http.ResponseController (Flush, deadline, ...)
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
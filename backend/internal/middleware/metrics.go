@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"repository-un/internal/config"
+	"repository-un/internal/metrics"
+	"repository-un/internal/router"
+)
+
+// MetricsAuthMiddleware melindungi /metrics. Scraper Prometheus mengirim
+// config.App.Metrics.Token sebagai bearer token; request tanpa token yang
+// cocok diperlakukan seperti route admin.
+func MetricsAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	admin := AdminMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := config.App.Metrics.Token
+		if token != "" && subtle.ConstantTimeCompare([]byte(GetTokenFromHeader(r)), []byte(token)) == 1 {
+			next(w, r)
+			return
+		}
+		admin(w, r)
+	}
+}
+
+// MetricsMiddleware mencatat jumlah dan durasi request per route untuk
+// /metrics. Request yang tidak cocok dengan route mana pun (404, preflight
+// CORS) digabung dalam route "unmatched".
+func MetricsMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+
+		next(rec, r)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		route := router.Pattern(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		method := metricMethod(r.Method)
+
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// metricMethod membatasi label method agar method sembarang dari client
+// tidak menambah jumlah time series
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
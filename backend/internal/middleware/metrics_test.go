@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"repository-un/internal/config"
+	"repository-un/internal/jwtkeys"
+)
+
+func TestMetricsAuthMiddleware(t *testing.T) {
+	old, oldKeys := config.App.Metrics, config.JWTKeys
+	t.Cleanup(func() { config.App.Metrics, config.JWTKeys = old, oldKeys })
+
+	key, err := jwtkeys.NewSecretKey("", []byte("jwt-secret-jwt-secret-jwt-secret-jwt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.JWTKeys, err = jwtkeys.NewKeySet(key); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := MetricsAuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	const token = "scrape-token-scrape-token-scrape-token"
+	tests := []struct {
+		name          string
+		configToken   string
+		authorization string
+		want          int
+	}{
+		{"valid token", token, "Bearer " + token, http.StatusOK},
+		{"wrong token", token, "Bearer salah", http.StatusUnauthorized},
+		{"no header", token, "", http.StatusUnauthorized},
+		{"token not configured", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.App.Metrics.Token = tt.configToken
+
+			r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"repository-un/internal/requestid"
+)
+
+// RequestIDMiddleware memberi setiap request ID unik yang dikirim balik
+// lewat header X-Request-ID dan dicantumkan di response error. ID dari
+// client (misalnya reverse proxy) dipakai jika formatnya aman.
+func RequestIDMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.FromHeader(r.Header.Get(requestid.Header))
+		w.Header().Set(requestid.Header, id)
+		next(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	}
+}
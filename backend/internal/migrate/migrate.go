@@ -0,0 +1,356 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID adalah kunci pg_advisory_lock agar dua server tidak menjalankan
+// migration bersamaan
+const lockID = 7311254019
+
+// filePattern mencocokkan nama file "<versi>_<nama>.up.sql" / ".down.sql"
+var filePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration adalah satu perubahan skema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+
+	// Checksum adalah hash SHA-256 isi file up, dicatat saat migration
+	// dijalankan untuk mendeteksi file yang diubah setelahnya
+	Checksum string
+}
+
+// Status adalah status satu migration di database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+
+	// Modified bernilai true jika file up berubah setelah dijalankan
+	Modified bool
+}
+
+// Load membaca migration dari fsys. Setiap versi wajib punya file up;
+// file down opsional (tanpa file down migration tidak bisa di-rollback).
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+
+		m := filePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("nama file migration tidak valid: %s", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("versi migration %d dipakai dua nama: %s dan %s", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(data)
+			// Akhir baris dinormalkan agar checkout Windows (CRLF) tidak
+			// dianggap file yang berubah
+			sum := sha256.Sum256([]byte(strings.ReplaceAll(mig.Up, "\r\n", "\n")))
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Checksum == "" {
+			return nil, fmt.Errorf("migration %03d_%s tidak punya file up", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator menjalankan migration terhadap database
+type Migrator struct {
+	DB         *pgxpool.Pool
+	Migrations []Migration
+}
+
+// New membuat migrator dari file migration di fsys
+func New(db *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// applied menyimpan versi yang sudah dijalankan beserta checksum-nya
+type applied struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock membuat tabel schema_migrations jika belum ada lalu menjalankan
+// fn sambil memegang advisory lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return err
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	_, err = conn.Exec(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+		     version INTEGER PRIMARY KEY,
+		     name TEXT NOT NULL,
+		     checksum CHAR(64) NOT NULL,
+		     applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+		 )`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// loadApplied membaca isi tabel schema_migrations
+func loadApplied(ctx context.Context, conn *pgxpool.Conn) (map[int]applied, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int]applied{}
+	for rows.Next() {
+		var version int
+		var a applied
+		if err := rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = a
+	}
+	return result, rows.Err()
+}
+
+// verify memastikan migration yang sudah dijalankan masih sama dengan file
+// di binary dan tidak ada versi di database yang tidak dikenal binary ini
+func (m *Migrator) verify(done map[int]applied) error {
+	var problems []string
+	known := map[int]bool{}
+
+	for _, mig := range m.Migrations {
+		known[mig.Version] = true
+		if a, ok := done[mig.Version]; ok && a.checksum != mig.Checksum {
+			problems = append(problems, fmt.Sprintf("%03d_%s: checksum berbeda, file diubah setelah dijalankan", mig.Version, mig.Name))
+		}
+	}
+	for version := range done {
+		if !known[version] {
+			problems = append(problems, fmt.Sprintf("versi %03d ada di database tetapi tidak dikenal binary ini", version))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("migration tidak konsisten:\n  - " + strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan, masing-masing
+// dalam satu transaksi. Mengembalikan migration yang dijalankan.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var ran []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %03d_%s gagal: %w", mig.Version, mig.Name, err)
+			}
+			ran = append(ran, mig)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down me-rollback steps migration terakhir yang sudah dijalankan.
+// Mengembalikan migration yang di-rollback.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			mig := m.Migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %03d_%s tidak punya file down", mig.Version, mig.Name)
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %03d_%s gagal: %w", mig.Version, mig.Name, err)
+			}
+			rolledBack = append(rolledBack, mig)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Force menandai semua migration sampai version sebagai sudah dijalankan
+// tanpa menjalankan SQL-nya, dan sisanya sebagai belum dijalankan. Dipakai
+// untuk database lama yang skemanya dibuat manual sebelum ada runner ini.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	found := version == 0
+	for _, mig := range m.Migrations {
+		if mig.Version == version {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("versi migration %d tidak dikenal", version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+				return err
+			}
+			for _, mig := range m.Migrations {
+				if mig.Version > version {
+					break
+				}
+				_, err := tx.Exec(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// Status mengembalikan status semua migration
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var result []Status
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			s := Status{Migration: mig}
+			if a, ok := done[mig.Version]; ok {
+				s.Applied = true
+				s.AppliedAt = &a.appliedAt
+				s.Modified = a.checksum != mig.Checksum
+			}
+			result = append(result, s)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Pending mengembalikan migration yang belum dijalankan. Error jika
+// migration yang sudah dijalankan tidak cocok dengan binary.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	var pending []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+
+		for _, mig := range m.Migrations {
+			if _, ok := done[mig.Version]; !ok {
+				pending = append(pending, mig)
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
@@ -4,27 +4,158 @@ import "time"
 
 // Document mewakili struktur dokumen dalam database
 type Document struct {
-	ID        string    `json:"id"`
-	Judul     string    `json:"judul"`
-	Penulis   string    `json:"penulis"`
-	JenisFile string    `json:"jenis_file"`
-	FilePath  string    `json:"file_path,omitempty"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string  `json:"id"`
+	Judul     string  `json:"judul"`
+	Penulis   string  `json:"penulis"`
+	JenisFile string  `json:"jenis_file"`
+	FilePath  string  `json:"file_path,omitempty"`
+	MimeType  string  `json:"mime_type"`
+	Status    string  `json:"status"`
+	OwnerID   *string `json:"owner_id,omitempty"`
+
+	// Metadata Dublin Core, list dokumen hanya berisi kolom di atas
+	Authors     []string             `json:"authors,omitempty"`
+	Advisors    []string             `json:"advisors,omitempty"`
+	Abstract    string               `json:"abstract,omitempty"`
+	Keywords    []string             `json:"keywords,omitempty"`
+	Year        *int                 `json:"year,omitempty"`
+	Faculty     string               `json:"faculty,omitempty"`
+	Department  string               `json:"department,omitempty"`
+	Language    string               `json:"language,omitempty"`
+	Publisher   string               `json:"publisher,omitempty"`
+	Identifiers []DocumentIdentifier `json:"identifiers,omitempty"`
+
+	// ProcessingStatus adalah status pemrosesan file versi aktif
+	// (pending, processing, ready, failed)
+	ProcessingStatus string    `json:"processing_status"`
+	ProcessingError  *string   `json:"processing_error,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
-// CreateDocumentRequest adalah request body untuk membuat dokumen baru
+// CreateDocumentRequest adalah metadata dokumen baru. Untuk upload multipart
+// dikirim sebagai JSON pada field form "metadata".
 type CreateDocumentRequest struct {
 	Title    string `json:"title"`
-	Author   string `json:"author"`
 	Category string `json:"category"`
-	Status   string `json:"status"`
+
+	// Author adalah penulis tunggal untuk client lama, dipakai jika Authors kosong
+	Author string `json:"author,omitempty"`
+
+	Authors     []string             `json:"authors"`
+	Advisors    []string             `json:"advisors"`
+	Abstract    string               `json:"abstract"`
+	Keywords    []string             `json:"keywords"`
+	Year        *int                 `json:"year"`
+	Faculty     string               `json:"faculty"`
+	Department  string               `json:"department"`
+	Language    string               `json:"language"`
+	Publisher   string               `json:"publisher"`
+	Identifiers []DocumentIdentifier `json:"identifiers"`
 }
 
-// UpdateDocumentRequest adalah request body untuk update dokumen
-type UpdateDocumentRequest struct {
-	Title    string `json:"title"`
-	Author   string `json:"author"`
-	Category string `json:"category"`
-	Status   string `json:"status"`
+// UpdateDocumentRequest adalah metadata untuk update dokumen.
+// Seluruh metadata diganti, field yang kosong ikut dikosongkan.
+type UpdateDocumentRequest = CreateDocumentRequest
+
+// DocumentIdentifier adalah identifier dokumen, misalnya DOI atau ISBN
+type DocumentIdentifier struct {
+	Scheme string `json:"scheme"`
+	Value  string `json:"value"`
+}
+
+// DocumentVersion mewakili satu file yang pernah dilampirkan ke dokumen
+type DocumentVersion struct {
+	ID            string    `json:"id"`
+	DocumentID    string    `json:"document_id"`
+	VersionNumber int       `json:"version_number"`
+	FilePath      string    `json:"-"`
+	OriginalName  string    `json:"original_name"`
+	FileSize      int64     `json:"file_size"`
+	MimeType      string    `json:"mime_type"`
+	SHA256        string    `json:"sha256,omitempty"`
+	ScanStatus    string    `json:"scan_status"`
+	SplitDir      string    `json:"-"`
+	PageCount     int       `json:"page_count"`
+	CreatedBy     *string   `json:"created_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	IsCurrent     bool      `json:"is_current"`
+}
+
+// DocumentList adalah response list dokumen dengan informasi pagination
+type DocumentList struct {
+	Data       []Document `json:"data"`
+	Total      int        `json:"total"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	TotalPages int        `json:"total_pages"`
+}
+
+// SearchResult adalah satu hasil pencarian full-text dokumen
+type SearchResult struct {
+	Document
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+	Page    *int    `json:"page,omitempty"`
+}
+
+// SearchResponse adalah response endpoint pencarian dokumen
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+	Total   int            `json:"total"`
+	Page    int            `json:"page"`
+	Limit   int            `json:"limit"`
+}
+
+// StatusHistory adalah satu catatan perpindahan status dokumen
+type StatusHistory struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	Action     string    `json:"action"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    *string   `json:"actor_id"`
+	ActorName  *string   `json:"actor_name,omitempty"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TransitionRequest adalah request body untuk endpoint transisi status
+type TransitionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// TransitionResponse adalah response setelah status dokumen berpindah
+type TransitionResponse struct {
+	ID               string        `json:"id"`
+	Status           string        `json:"status"`
+	History          StatusHistory `json:"history"`
+	AvailableActions []string      `json:"available_actions"`
+}
+
+// QuarantinedFile adalah file versi dokumen yang dikarantina karena
+// terdeteksi malware (infected) atau gagal dipindai (error)
+type QuarantinedFile struct {
+	DocumentID    string     `json:"document_id"`
+	Title         string     `json:"title"`
+	OwnerID       *string    `json:"owner_id"`
+	VersionID     string     `json:"version_id"`
+	VersionNumber int        `json:"version_number"`
+	OriginalName  string     `json:"original_name"`
+	FileSize      int64      `json:"file_size"`
+	MimeType      string     `json:"mime_type"`
+	ScanStatus    string     `json:"scan_status"`
+	ScanResult    string     `json:"scan_result"`
+	ScannedAt     *time.Time `json:"scanned_at"`
+	IsCurrent     bool       `json:"is_current"`
+}
+
+// QuarantineList adalah response list file karantina dengan informasi pagination
+type QuarantineList struct {
+	Data       []QuarantinedFile `json:"data"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
 }
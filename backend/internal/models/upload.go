@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// UploadSession adalah sesi upload bertahap (resumable). File dikirim per
+// potongan berukuran ChunkSize, potongan terakhir boleh lebih kecil.
+type UploadSession struct {
+	ID             string    `json:"id"`
+	DocumentID     *string   `json:"document_id"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	TotalSize      int64     `json:"size"`
+	ChunkSize      int64     `json:"chunk_size"`
+	TotalChunks    int       `json:"total_chunks"`
+	SHA256         *string   `json:"sha256"`
+	ReceivedChunks []int     `json:"received_chunks"`
+	MissingChunks  []int     `json:"missing_chunks"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateUploadRequest memulai sesi upload. DocumentID diisi untuk
+// mengupload versi baru dokumen yang sudah ada; SHA256 (opsional) adalah
+// checksum seluruh file yang dicek saat complete.
+type CreateUploadRequest struct {
+	FileName    string `json:"file_name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	SHA256      string `json:"sha256"`
+	DocumentID  string `json:"document_id"`
+}
+
+// UploadChunk adalah potongan yang sudah diterima
+type UploadChunk struct {
+	Number    int       `json:"number"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
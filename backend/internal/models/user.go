@@ -52,8 +52,24 @@ type UpdateUserRequest struct {
 	Role     string `json:"role"`
 }
 
-// AuthResponse adalah response setelah login/register berhasil
+// AuthResponse adalah response setelah login/register/refresh berhasil.
+// Token adalah access token berumur pendek (ExpiresIn detik); sesi
+// diperpanjang dengan mengirim RefreshToken ke /api/auth/refresh.
 type AuthResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresIn    int          `json:"expires_in"`
+	User         UserResponse `json:"user"`
+}
+
+// RefreshRequest adalah request body untuk refresh access token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest adalah request body untuk logout. All mencabut semua
+// sesi user di semua perangkat.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"`
 }
@@ -0,0 +1,41 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header adalah header tempat request ID diterima dan dikirim balik
+const Header = "X-Request-ID"
+
+// validID membatasi request ID dari client agar aman ditulis ke log
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,64}$`)
+
+type contextKey struct{}
+
+// New membuat request ID baru
+func New() string {
+	return uuid.NewString()
+}
+
+// FromHeader mengembalikan request ID dari client jika formatnya aman,
+// atau membuat yang baru
+func FromHeader(value string) string {
+	if validID.MatchString(value) {
+		return value
+	}
+	return New()
+}
+
+// NewContext menyimpan request ID di context
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext mengambil request ID dari context, "" jika tidak ada
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
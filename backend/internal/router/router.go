@@ -0,0 +1,202 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"repository-un/internal/apierror"
+
+	"github.com/google/uuid"
+)
+
+// Middleware membungkus handler, sama dengan bentuk middleware di
+// package middleware (AuthMiddleware, AdminMiddleware, ...)
+type Middleware func(http.HandlerFunc) http.HandlerFunc
+
+// ParamValidator mengecek nilai satu path parameter
+type ParamValidator func(string) bool
+
+// routes berisi data yang dipakai bersama oleh router dan semua grupnya
+type routes struct {
+	mux    *http.ServeMux
+	global []Middleware
+	params map[string]ParamValidator
+}
+
+// Router mendaftarkan route berdasarkan method dan pola path ServeMux
+// Go 1.22, contoh "/api/documents/{id}". Path parameter dibaca dengan
+// r.PathValue("id"). Route yang tidak ada dijawab 404 dan method yang
+// tidak didukung dijawab 405 dalam bentuk JSON.
+type Router struct {
+	*routes
+	middleware []Middleware
+}
+
+// New membuat router kosong
+func New() *Router {
+	return &Router{routes: &routes{
+		mux:    http.NewServeMux(),
+		params: map[string]ParamValidator{},
+	}}
+}
+
+// Use menambahkan middleware yang dijalankan untuk setiap request,
+// termasuk request yang berakhir 404/405 (misalnya CORS)
+func (rt *Router) Use(mw ...Middleware) {
+	rt.global = append(rt.global, mw...)
+}
+
+// With mengembalikan grup route yang memakai middleware tambahan.
+// Middleware dijalankan berurutan, yang pertama paling luar.
+func (rt *Router) With(mw ...Middleware) *Router {
+	middleware := append([]Middleware{}, rt.middleware...)
+	return &Router{routes: rt.routes, middleware: append(middleware, mw...)}
+}
+
+// Param mendaftarkan tipe path parameter bernama name. Route dengan
+// parameter itu menjawab 404 jika nilainya tidak valid, sehingga handler
+// selalu menerima nilai yang sudah benar.
+func (rt *Router) Param(name string, valid ParamValidator) {
+	rt.params[name] = valid
+}
+
+// Handle mendaftarkan handler untuk method dan pola path
+func (rt *Router) Handle(method, pattern string, h http.HandlerFunc) {
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		h = rt.middleware[i](h)
+	}
+	rt.mux.HandleFunc(method+" "+pattern, rt.checkParams(pattern, h))
+}
+
+// Get mendaftarkan handler GET (otomatis juga HEAD)
+func (rt *Router) Get(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodGet, pattern, h)
+}
+
+// Post mendaftarkan handler POST
+func (rt *Router) Post(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodPost, pattern, h)
+}
+
+// Put mendaftarkan handler PUT
+func (rt *Router) Put(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodPut, pattern, h)
+}
+
+// Delete mendaftarkan handler DELETE
+func (rt *Router) Delete(pattern string, h http.HandlerFunc) {
+	rt.Handle(http.MethodDelete, pattern, h)
+}
+
+// checkParams membungkus h agar path parameter bertipe divalidasi dulu
+func (rt *Router) checkParams(pattern string, h http.HandlerFunc) http.HandlerFunc {
+	var names []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.TrimSuffix(strings.TrimSuffix(segment[1:len(segment)-1], "..."), "$")
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range names {
+			if valid, ok := rt.params[name]; ok && !valid(r.PathValue(name)) {
+				NotFound(w, r)
+				return
+			}
+		}
+		h(w, r)
+	}
+}
+
+// ServeHTTP menjalankan middleware global lalu route yang cocok
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := rt.dispatch
+	for i := len(rt.global) - 1; i >= 0; i-- {
+		h = rt.global[i](h)
+	}
+	h(w, r.WithContext(context.WithValue(r.Context(), patternKey{}, new(string))))
+}
+
+// patternKey adalah key context tempat dispatch mencatat pola route
+type patternKey struct{}
+
+// Pattern mengembalikan pola route yang cocok tanpa method, misalnya
+// "/api/documents/{id}", atau "" jika tidak ada route yang cocok.
+// Middleware global baru bisa membacanya setelah handler selesai.
+func Pattern(r *http.Request) string {
+	if p, ok := r.Context().Value(patternKey{}).(*string); ok {
+		return *p
+	}
+	return ""
+}
+
+// dispatch meneruskan request ke ServeMux. Jika tidak ada pola yang
+// cocok, jawaban bawaan ServeMux (teks biasa) diganti dengan JSON.
+func (rt *Router) dispatch(w http.ResponseWriter, r *http.Request) {
+	h, pattern := rt.mux.Handler(r)
+	if p, ok := r.Context().Value(patternKey{}).(*string); ok && pattern != "" {
+		_, *p, _ = strings.Cut(pattern, " ")
+	}
+	if pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	// Handler bawaan untuk 404/405 hanya menulis status dan header Allow
+	rec := &statusRecorder{header: http.Header{}}
+	h.ServeHTTP(rec, r)
+	if rec.status == http.StatusMethodNotAllowed {
+		w.Header().Set("Allow", rec.header.Get("Allow"))
+		apierror.Write(w, r, apierror.MethodNotAllowed)
+		return
+	}
+	NotFound(w, r)
+}
+
+// NotFound menjawab 404 dalam bentuk JSON
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	apierror.Write(w, r, apierror.NotFound)
+}
+
+// statusRecorder menampung response handler bawaan ServeMux
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header { return s.header }
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+}
+
+// UUID menerima UUID seperti id dokumen dan user
+func UUID(s string) bool {
+	return uuid.Validate(s) == nil
+}
+
+// PositiveInt menerima bilangan bulat mulai dari 1, misalnya nomor halaman
+func PositiveInt(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 1
+}
+
+// IntParam membaca path parameter angka yang sudah divalidasi lewat Param
+func IntParam(r *http.Request, name string) int {
+	n, _ := strconv.Atoi(r.PathValue(name))
+	return n
+}
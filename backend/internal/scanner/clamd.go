@@ -0,0 +1,181 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// instreamChunk adalah ukuran potongan data yang dikirim ke clamd
+const instreamChunk = 64 << 10
+
+// maxReply membatasi panjang jawaban clamd yang dibaca
+const maxReply = 4 << 10
+
+// Clamd memindai file lewat daemon ClamAV dengan perintah INSTREAM, jadi
+// clamd tidak perlu bisa membaca folder upload atau bucket S3
+type Clamd struct {
+	Network string // "tcp" atau "unix"
+	Address string
+	Timeout time.Duration
+}
+
+// NewClamd membuat scanner clamd dari alamat seperti "tcp://127.0.0.1:3310",
+// "127.0.0.1:3310" atau "unix:///run/clamav/clamd.ctl". timeout membatasi
+// satu pemindaian jika ctx tidak punya deadline.
+func NewClamd(address string, timeout time.Duration) (*Clamd, error) {
+	network, addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &Clamd{Network: network, Address: addr, Timeout: timeout}, nil
+}
+
+// ParseAddress memisahkan alamat clamd menjadi network dan address untuk net.Dial
+func ParseAddress(address string) (network, addr string, err error) {
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+	default:
+		network, addr = "tcp", address
+	}
+
+	if network == "tcp" {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return "", "", fmt.Errorf("alamat clamd tidak valid: %q", address)
+		}
+	}
+	if addr == "" {
+		return "", "", fmt.Errorf("alamat clamd tidak valid: %q", address)
+	}
+	return network, addr, nil
+}
+
+// Scan mengirim isi r ke clamd dan membaca hasilnya
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return Result{}, fmt.Errorf("clamd: %w", err)
+	}
+
+	// Setiap potongan diawali panjangnya (uint32 big-endian), potongan
+	// berukuran 0 menandai akhir file
+	buf := make([]byte, 4+instreamChunk)
+	for {
+		n, rerr := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				// clamd memutus koneksi jika file melebihi StreamMaxLength,
+				// alasannya dikirim sebelum koneksi ditutup
+				if reply, rerr := readReply(conn); rerr == nil && reply != "" {
+					return parseReply(reply)
+				}
+				return Result{}, fmt.Errorf("clamd: %w", err)
+			}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return Result{}, rerr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Result{}, fmt.Errorf("clamd: %w", err)
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return Result{}, err
+	}
+	return parseReply(reply)
+}
+
+// Check mengirim PING ke clamd
+func (c *Clamd) Check(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := io.WriteString(conn, "zPING\x00"); err != nil {
+		return fmt.Errorf("clamd: %w", err)
+	}
+	reply, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd: jawaban PING tidak dikenal: %q", reply)
+	}
+	return nil
+}
+
+// dial membuka koneksi ke clamd. Deadline koneksi mengikuti ctx, atau
+// Timeout jika ctx tidak punya deadline; pembatalan ctx memutus koneksi.
+func (c *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return nil, fmt.Errorf("clamd: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok && c.Timeout > 0 {
+		deadline = time.Now().Add(c.Timeout)
+	}
+	conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	return &stopConn{Conn: conn, stop: stop}, nil
+}
+
+// stopConn melepas pemantauan ctx saat koneksi ditutup
+type stopConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *stopConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// readReply membaca satu jawaban clamd yang diakhiri byte NUL
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(io.LimitReader(conn, maxReply)).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", fmt.Errorf("clamd: gagal membaca jawaban: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// parseReply membaca jawaban INSTREAM, contoh "stream: OK",
+// "stream: Eicar-Signature FOUND" atau "INSTREAM size limit exceeded. ERROR"
+func parseReply(reply string) (Result, error) {
+	body := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case body == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(body, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(body, " FOUND")}, nil
+	case strings.HasSuffix(body, " ERROR"):
+		return Result{}, fmt.Errorf("clamd: %s", strings.TrimSuffix(body, " ERROR"))
+	}
+	return Result{}, fmt.Errorf("clamd: jawaban tidak dikenal: %q", reply)
+}
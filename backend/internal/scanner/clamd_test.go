@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClamd menerima satu perintah INSTREAM per koneksi, mencatat panjang
+// setiap potongan dan isi file, lalu membalas dengan reply. reply kosong
+// berarti koneksi diputus tanpa jawaban.
+type fakeClamd struct {
+	reply  string
+	chunks chan []uint32
+	data   chan []byte
+}
+
+func startFakeClamd(t *testing.T, reply string) (*fakeClamd, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f := &fakeClamd{reply: reply, chunks: make(chan []uint32, 1), data: make(chan []byte, 1)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.serve(t, conn)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeClamd) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	cmd, err := br.ReadString(0)
+	if err != nil {
+		t.Errorf("fake clamd: gagal membaca perintah: %v", err)
+		return
+	}
+	if cmd == "zPING\x00" {
+		io.WriteString(conn, "PONG\x00")
+		return
+	}
+	if cmd != "zINSTREAM\x00" {
+		t.Errorf("fake clamd: perintah = %q, want zINSTREAM", cmd)
+		return
+	}
+
+	var sizes []uint32
+	var data bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(br, binary.BigEndian, &size); err != nil {
+			t.Errorf("fake clamd: gagal membaca panjang potongan: %v", err)
+			return
+		}
+		sizes = append(sizes, size)
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&data, br, int64(size)); err != nil {
+			t.Errorf("fake clamd: potongan terpotong: %v", err)
+			return
+		}
+	}
+	f.chunks <- sizes
+	f.data <- data.Bytes()
+
+	if f.reply != "" {
+		io.WriteString(conn, f.reply+"\x00")
+	}
+}
+
+func TestClamdScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		want      Result
+		wantErr   string
+		sizeBytes int
+	}{
+		{"clean", "stream: OK", Result{}, "", 10},
+		{"infected", "stream: Eicar-Test-Signature FOUND", Result{Infected: true, Signature: "Eicar-Test-Signature"}, "", 68},
+		{"size limit", "INSTREAM size limit exceeded. ERROR", Result{}, "INSTREAM size limit exceeded.", 2*instreamChunk + 1},
+		{"dropped", "", Result{}, "gagal membaca jawaban", 10},
+		{"empty file", "stream: OK", Result{}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, addr := startFakeClamd(t, tt.reply)
+			c, err := NewClamd("tcp://"+addr, 5*time.Second)
+			if err != nil {
+				t.Fatalf("NewClamd: %v", err)
+			}
+
+			input := bytes.Repeat([]byte("x"), tt.sizeBytes)
+			got, err := c.Scan(context.Background(), bytes.NewReader(input))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Scan error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Scan error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Scan = %+v, want %+v", got, tt.want)
+			}
+
+			// Setiap potongan paling besar instreamChunk dan diakhiri potongan 0
+			sizes := <-fake.chunks
+			var want []uint32
+			for n := tt.sizeBytes; n > 0; n -= instreamChunk {
+				want = append(want, uint32(min(n, instreamChunk)))
+			}
+			want = append(want, 0)
+			if !slices.Equal(sizes, want) {
+				t.Errorf("potongan = %v, want %v", sizes, want)
+			}
+			if data := <-fake.data; !bytes.Equal(data, input) {
+				t.Errorf("isi yang diterima clamd berbeda (%d byte, want %d)", len(data), len(input))
+			}
+		})
+	}
+}
+
+func TestClamdCheck(t *testing.T) {
+	_, addr := startFakeClamd(t, "")
+	c, err := NewClamd(addr, time.Second)
+	if err != nil {
+		t.Fatalf("NewClamd: %v", err)
+	}
+	if err := c.Check(context.Background()); err != nil {
+		t.Errorf("Check: %v", err)
+	}
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := ln.Addr().String()
+	ln.Close()
+	c.Address = closed
+	if err := c.Check(context.Background()); err == nil {
+		t.Error("Check ke alamat tertutup tidak error")
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		in, network, addr string
+		wantErr           bool
+	}{
+		{"tcp://127.0.0.1:3310", "tcp", "127.0.0.1:3310", false},
+		{"127.0.0.1:3310", "tcp", "127.0.0.1:3310", false},
+		{"unix:///run/clamav/clamd.ctl", "unix", "/run/clamav/clamd.ctl", false},
+		{"127.0.0.1", "", "", true},
+		{"unix://", "", "", true},
+	}
+	for _, tt := range tests {
+		network, addr, err := ParseAddress(tt.in)
+		if (err != nil) != tt.wantErr || network != tt.network || addr != tt.addr {
+			t.Errorf("ParseAddress(%q) = %q, %q, %v", tt.in, network, addr, err)
+		}
+	}
+}
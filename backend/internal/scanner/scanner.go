@@ -0,0 +1,31 @@
+// Package scanner memindai file upload dari malware sebelum dokumen bisa
+// didownload.
+package scanner
+
+import (
+	"context"
+	"io"
+)
+
+// Result adalah hasil pemindaian satu file
+type Result struct {
+	Infected bool
+	// Signature adalah nama malware yang ditemukan, kosong jika bersih
+	Signature string
+}
+
+// Scanner memindai isi file. Error berarti file tidak bisa dipindai
+// (daemon mati, timeout, file terlalu besar), bukan berarti terinfeksi.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+
+	// Check memastikan scanner bisa dipakai, dipakai oleh /readyz
+	Check(ctx context.Context) error
+}
+
+// Nop adalah scanner yang menganggap semua file bersih, dipakai jika
+// pemindaian tidak diaktifkan
+type Nop struct{}
+
+func (Nop) Scan(ctx context.Context, r io.Reader) (Result, error) { return Result{}, nil }
+func (Nop) Check(ctx context.Context) error                       { return nil }
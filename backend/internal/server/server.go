@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"repository-un/internal/config"
+)
+
+// New membuat http.Server dengan timeout dari konfigurasi. Error internal
+// http.Server (misalnya TLS handshake gagal) ditulis ke logger slog.
+func New(handler http.Handler, c config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              c.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: c.ReadHeaderTimeout,
+		ReadTimeout:       c.ReadTimeout,
+		WriteTimeout:      c.WriteTimeout,
+		IdleTimeout:       c.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// Run menjalankan HTTP server (atau HTTPS jika sertifikat dikonfigurasi)
+// sampai ctx selesai. Setelah itu server berhenti menerima koneksi baru dan
+// menunggu request yang sedang berjalan, misalnya upload, paling lama
+// c.ShutdownTimeout.
+func Run(ctx context.Context, handler http.Handler, c config.ServerConfig) error {
+	srv := New(handler, c)
+
+	useTLS := c.TLS.CertFile != ""
+	if useTLS {
+		certs, err := NewCertReloader(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+
+		// SIGHUP memuat ulang sertifikat tanpa menunggu pengecekan berkala
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		go func() {
+			for range hup {
+				if err := certs.Reload(); err != nil {
+					slog.Error("Gagal memuat ulang sertifikat TLS", "error", err)
+				} else {
+					slog.Info("✅ Sertifikat TLS dimuat ulang")
+				}
+			}
+		}()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if useTLS {
+			errCh <- srv.ListenAndServeTLS("", "")
+		} else {
+			errCh <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Menghentikan server, menunggu request yang sedang berjalan...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), c.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
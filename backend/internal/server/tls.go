@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// certCheckInterval adalah jeda minimal pengecekan perubahan file sertifikat
+const certCheckInterval = 30 * time.Second
+
+// CertReloader menyimpan sertifikat TLS dan memuat ulang file-nya saat
+// berubah (misalnya diperbarui certbot) tanpa restart server
+type CertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewCertReloader memuat sertifikat dari certFile dan keyFile (PEM)
+func NewCertReloader(certFile, keyFile string) (*CertReloader, error) {
+	r := &CertReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload membaca ulang file sertifikat. Jika gagal, sertifikat lama tetap dipakai.
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = r.latestModTime()
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate dipakai sebagai tls.Config.GetCertificate. File sertifikat
+// dicek paling sering sekali per certCheckInterval.
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	cert, modTime, checkedAt := r.cert, r.modTime, r.checkedAt
+	r.mu.RUnlock()
+
+	if time.Since(checkedAt) < certCheckInterval {
+		return cert, nil
+	}
+
+	r.mu.Lock()
+	r.checkedAt = time.Now()
+	r.mu.Unlock()
+
+	if r.latestModTime().After(modTime) {
+		if err := r.Reload(); err != nil {
+			slog.Error("Gagal memuat ulang sertifikat TLS", "error", err)
+		} else {
+			slog.Info("✅ Sertifikat TLS dimuat ulang")
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// latestModTime mengembalikan waktu ubah terbaru file sertifikat dan key
+func (r *CertReloader) latestModTime() time.Time {
+	var latest time.Time
+	for _, f := range []string{r.certFile, r.keyFile} {
+		if info, err := os.Stat(f); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
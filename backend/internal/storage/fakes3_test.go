@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 adalah server S3 minimal di memori (path-style) untuk menguji
+// S3 tanpa MinIO sungguhan. Hanya operasi yang dipakai S3 yang didukung.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+type fakeObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// newFakeS3 menjalankan fakeS3 dan mengembalikan endpoint host:port-nya
+func newFakeS3(t *testing.T) string {
+	t.Helper()
+	f := &fakeS3{buckets: map[string]map[string]fakeObject{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	u, _ := url.Parse(srv.URL)
+	return u.Host
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	q := r.URL.Query()
+
+	if key == "" {
+		f.serveBucket(w, r, bucket, q)
+		return
+	}
+
+	objects, ok := f.buckets[bucket]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		if src := r.Header.Get("X-Amz-Copy-Source"); src != "" {
+			src, _ = url.PathUnescape(src)
+			_, srcKey, _ := strings.Cut(strings.TrimPrefix(src, "/"), "/")
+			obj, ok := objects[srcKey]
+			if !ok {
+				writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+				return
+			}
+			objects[key] = fakeObject{data: obj.data, modTime: time.Now()}
+			fmt.Fprintf(w, `<CopyObjectResult><LastModified>%s</LastModified><ETag>"x"</ETag></CopyObjectResult>`,
+				time.Now().UTC().Format(time.RFC3339))
+			return
+		}
+		data, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		objects[key] = fakeObject{data: data, modTime: time.Now()}
+		w.Header().Set("ETag", `"x"`)
+
+	case http.MethodGet, http.MethodHead:
+		obj, ok := objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"x"`)
+		w.Header().Set("Last-Modified", obj.modTime.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, "", obj.modTime, bytes.NewReader(obj.data))
+
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// serveBucket menangani operasi pada bucket: cek ada, buat, list dan
+// hapus banyak object
+func (f *fakeS3) serveBucket(w http.ResponseWriter, r *http.Request, bucket string, q url.Values) {
+	objects, exists := f.buckets[bucket]
+
+	switch {
+	case r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+
+	case r.Method == http.MethodPut:
+		if !exists {
+			f.buckets[bucket] = map[string]fakeObject{}
+		}
+
+	case r.Method == http.MethodGet && q.Has("location"):
+		fmt.Fprint(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+
+	case r.Method == http.MethodGet && exists:
+		prefix := q.Get("prefix")
+		var keys []string
+		for k := range objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var b strings.Builder
+		fmt.Fprintf(&b, `<ListBucketResult><Name>%s</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><IsTruncated>false</IsTruncated>`,
+			bucket, prefix, len(keys))
+		for _, k := range keys {
+			b.WriteString("<Contents><Key>")
+			xml.EscapeText(&b, []byte(k))
+			fmt.Fprintf(&b, "</Key><LastModified>%s</LastModified><Size>%d</Size><ETag>\"x\"</ETag></Contents>",
+				objects[k].modTime.UTC().Format(time.RFC3339), len(objects[k].data))
+		}
+		b.WriteString("</ListBucketResult>")
+		fmt.Fprint(w, b.String())
+
+	case r.Method == http.MethodPost && q.Has("delete") && exists:
+		var req struct {
+			Objects []struct {
+				Key string `xml:"Key"`
+			} `xml:"Object"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeS3Error(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var b strings.Builder
+		b.WriteString("<DeleteResult>")
+		for _, o := range req.Objects {
+			delete(objects, o.Key)
+			b.WriteString("<Deleted><Key>")
+			xml.EscapeText(&b, []byte(o.Key))
+			b.WriteString("</Key></Deleted>")
+		}
+		b.WriteString("</DeleteResult>")
+		fmt.Fprint(w, b.String())
+
+	default:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+	}
+}
+
+// readS3Body membaca isi object, termasuk format aws-chunked
+// ("<ukuran hex>;chunk-signature=...\r\n<data>\r\n")
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") &&
+		!strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size)
+		if _, err := io.ReadFull(br, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+		br.ReadString('\n')
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
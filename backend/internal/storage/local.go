@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local menyimpan file di filesystem lokal di bawah direktori Root
+type Local struct {
+	Root string
+}
+
+// NewLocal membuat storage lokal dan memastikan direktori root ada
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &Local{Root: root}, nil
+}
+
+// path mengubah key menjadi path di filesystem
+func (l *Local) path(key string) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.Root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put menyimpan file ke disk. File ditulis ke file sementara lalu di-rename
+// agar pembaca tidak pernah melihat file yang setengah jadi.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+// Open membuka file dari disk
+func (l *Local) Open(ctx context.Context, key string) (io.ReadSeekCloser, ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, ObjectInfo{}, mapLocalError(err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, ObjectInfo{}, mapLocalError(err)
+	}
+	if fi.IsDir() {
+		f.Close()
+		return nil, ObjectInfo{}, ErrNotExist
+	}
+
+	return f, ObjectInfo{Key: key, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// Stat mengambil informasi file
+func (l *Local) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return ObjectInfo{}, mapLocalError(err)
+	}
+	if fi.IsDir() {
+		return ObjectInfo{}, ErrNotExist
+	}
+
+	return ObjectInfo{Key: key, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// Move memindahkan file dengan rename sehingga dst tidak pernah terlihat
+// setengah jadi
+func (l *Local) Move(ctx context.Context, src, dst string) error {
+	from, err := l.path(src)
+	if err != nil {
+		return err
+	}
+	to, err := l.path(dst)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	return mapLocalError(os.Rename(from, to))
+}
+
+// Remove menghapus satu file
+func (l *Local) Remove(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// RemoveAll menghapus direktori beserta isinya
+func (l *Local) RemoveAll(ctx context.Context, dir string) error {
+	p, err := l.path(dir)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(p)
+}
+
+// List mengembalikan semua file di bawah dir, atau di bawah Root jika dir kosong
+func (l *Local) List(ctx context.Context, dir string) ([]ObjectInfo, error) {
+	p := l.Root
+	var err error
+	if dir != "" {
+		if p, err = l.path(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var objects []ObjectInfo
+	err = filepath.WalkDir(p, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Lewati direktori dan file sementara yang sedang ditulis
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(l.Root, fp)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, ObjectInfo{
+			Key:     filepath.ToSlash(rel),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		})
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return objects, err
+}
+
+// mapLocalError mengubah error filesystem menjadi error storage
+func mapLocalError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotExist
+	}
+	return err
+}
+
+// Check memastikan direktori root masih bisa ditulis
+func (l *Local) Check(ctx context.Context) error {
+	f, err := os.CreateTemp(l.Root, ".check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
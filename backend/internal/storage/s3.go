@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config berisi pengaturan koneksi ke storage S3-compatible
+// (AWS S3, MinIO, Ceph RGW, dll)
+type S3Config struct {
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Region          string
+	UseSSL          bool
+}
+
+// S3 menyimpan file sebagai object di bucket S3-compatible
+type S3 struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 membuat storage S3 dan membuat bucket jika belum ada
+func NewS3(ctx context.Context, cfg S3Config) (*S3, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &S3{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Put mengupload object ke bucket
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Open membuka object dari bucket
+func (s *S3) Open(ctx context.Context, key string) (io.ReadSeekCloser, ObjectInfo, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, ObjectInfo{}, err
+	}
+
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, mapS3Error(err)
+	}
+
+	// GetObject bersifat lazy, Stat memastikan object benar-benar ada
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, ObjectInfo{}, mapS3Error(err)
+	}
+
+	return obj, ObjectInfo{Key: key, Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+// Stat mengambil informasi object
+func (s *S3) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, mapS3Error(err)
+	}
+
+	return ObjectInfo{Key: key, Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+// Move menyalin object src ke dst di sisi server lalu menghapus src
+func (s *S3) Move(ctx context.Context, src, dst string) error {
+	src, err := CleanKey(src)
+	if err != nil {
+		return err
+	}
+	dst, err = CleanKey(dst)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.bucket, Object: dst},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: src})
+	if err != nil {
+		return mapS3Error(err)
+	}
+	return s.client.RemoveObject(ctx, s.bucket, src, minio.RemoveObjectOptions{})
+}
+
+// Remove menghapus satu object
+func (s *S3) Remove(ctx context.Context, key string) error {
+	key, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// RemoveAll menghapus semua object dengan prefix dir/
+func (s *S3) RemoveAll(ctx context.Context, dir string) error {
+	prefix, err := dirPrefix(dir)
+	if err != nil {
+		return err
+	}
+
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for result := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// List mengembalikan semua object dengan prefix dir/, atau seluruh bucket
+// jika dir kosong
+func (s *S3) List(ctx context.Context, dir string) ([]ObjectInfo, error) {
+	var prefix string
+	if dir != "" {
+		var err error
+		if prefix, err = dirPrefix(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var objects []ObjectInfo
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		objects = append(objects, ObjectInfo{
+			Key:     obj.Key,
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+		})
+	}
+	return objects, nil
+}
+
+// Check memastikan bucket masih bisa dijangkau
+func (s *S3) Check(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s tidak ada", s.bucket)
+	}
+	return nil
+}
+
+// dirPrefix mengubah nama direktori menjadi prefix object ("split/abc/")
+func dirPrefix(dir string) (string, error) {
+	cleaned, err := CleanKey(dir)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(cleaned, "/") + "/", nil
+}
+
+// mapS3Error mengubah error "NoSuchKey" menjadi ErrNotExist
+func mapS3Error(err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey" {
+		return ErrNotExist
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotExist dikembalikan ketika object yang diminta tidak ada di storage
+var ErrNotExist = errors.New("storage: object tidak ditemukan")
+
+// ErrInvalidKey dikembalikan ketika key berisi path yang tidak aman
+var ErrInvalidKey = errors.New("storage: key tidak valid")
+
+// ObjectInfo berisi informasi dasar sebuah object
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage adalah abstraksi tempat penyimpanan file dokumen.
+// Key selalu memakai pemisah "/" dan relatif terhadap root storage,
+// contoh: "3f2a....pdf" atau "split/<id>/3f2a..._1.pdf".
+type Storage interface {
+	// Put menyimpan isi r ke key. size boleh -1 jika tidak diketahui.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Open membuka object untuk dibaca. Pemanggil wajib menutup reader.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, ObjectInfo, error)
+
+	// Stat mengambil informasi object tanpa membaca isinya
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+
+	// Move memindahkan object src ke dst, menimpa dst jika sudah ada
+	Move(ctx context.Context, src, dst string) error
+
+	// Remove menghapus satu object. Tidak error jika object tidak ada.
+	Remove(ctx context.Context, key string) error
+
+	// RemoveAll menghapus semua object di bawah dir
+	RemoveAll(ctx context.Context, dir string) error
+
+	// List mengembalikan semua object di bawah dir (rekursif). dir kosong
+	// berarti seluruh isi storage.
+	List(ctx context.Context, dir string) ([]ObjectInfo, error)
+
+	// Check memastikan storage bisa ditulis, dipakai oleh /readyz
+	Check(ctx context.Context) error
+}
+
+// CleanKey menormalkan key dan menolak key yang keluar dari root storage
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
+
+// Join menggabungkan beberapa elemen menjadi satu key
+func Join(elem ...string) string {
+	return path.Join(elem...)
+}
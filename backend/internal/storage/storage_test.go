@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// backends mengembalikan semua implementasi Storage yang diuji: Local di
+// direktori sementara dan S3 yang diarahkan ke fakeS3
+func backends(t *testing.T) map[string]Storage {
+	t.Helper()
+
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	s3, err := NewS3(context.Background(), S3Config{
+		Endpoint:        newFakeS3(t),
+		AccessKeyID:     "test",
+		SecretAccessKey: "testsecret",
+		Bucket:          "repository",
+		Region:          "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+
+	return map[string]Storage{"local": local, "s3": s3}
+}
+
+func put(t *testing.T, s Storage, key, data string) {
+	t.Helper()
+	err := s.Put(context.Background(), key, strings.NewReader(data), int64(len(data)), "application/pdf")
+	if err != nil {
+		t.Fatalf("Put(%q): %v", key, err)
+	}
+}
+
+func TestStorage(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			put(t, s, "a.pdf", "isi a")
+			put(t, s, "split/doc1/1.pdf", "halaman 1")
+			put(t, s, "split/doc1/2.pdf", "halaman 2")
+			put(t, s, "split/doc10/1.pdf", "dokumen lain")
+			put(t, s, "a.pdf", "isi a baru")
+
+			t.Run("Open", func(t *testing.T) {
+				tests := []struct {
+					key     string
+					want    string
+					wantErr error
+				}{
+					{"a.pdf", "isi a baru", nil},
+					{"split/doc1/2.pdf", "halaman 2", nil},
+					{"tidak-ada.pdf", "", ErrNotExist},
+					{"split/doc1", "", ErrNotExist},
+					{"../luar.pdf", "", ErrInvalidKey},
+				}
+				for _, tt := range tests {
+					f, info, err := s.Open(ctx, tt.key)
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("Open(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+						continue
+					}
+					if err != nil {
+						continue
+					}
+					data, _ := io.ReadAll(f)
+					f.Close()
+					if string(data) != tt.want || info.Size != int64(len(tt.want)) {
+						t.Errorf("Open(%q) = %q (size %d), want %q", tt.key, data, info.Size, tt.want)
+					}
+				}
+			})
+
+			t.Run("Stat", func(t *testing.T) {
+				tests := []struct {
+					key      string
+					wantSize int64
+					wantErr  error
+				}{
+					{"split/doc1/1.pdf", 9, nil},
+					{"split/doc1/3.pdf", 0, ErrNotExist},
+					{"/absolut.pdf", 0, ErrInvalidKey},
+				}
+				for _, tt := range tests {
+					info, err := s.Stat(ctx, tt.key)
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("Stat(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+						continue
+					}
+					if err == nil && (info.Size != tt.wantSize || info.Key != tt.key || info.ModTime.IsZero()) {
+						t.Errorf("Stat(%q) = %+v", tt.key, info)
+					}
+				}
+			})
+
+			t.Run("List", func(t *testing.T) {
+				tests := []struct {
+					dir  string
+					want []string
+				}{
+					{"split/doc1", []string{"split/doc1/1.pdf", "split/doc1/2.pdf"}},
+					{"split/doc10", []string{"split/doc10/1.pdf"}},
+					{"split", []string{"split/doc1/1.pdf", "split/doc1/2.pdf", "split/doc10/1.pdf"}},
+					{"tidak-ada", nil},
+					{"", []string{"a.pdf", "split/doc1/1.pdf", "split/doc1/2.pdf", "split/doc10/1.pdf"}},
+				}
+				for _, tt := range tests {
+					objects, err := s.List(ctx, tt.dir)
+					if err != nil {
+						t.Errorf("List(%q): %v", tt.dir, err)
+						continue
+					}
+					if got := keys(objects); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+						t.Errorf("List(%q) = %v, want %v", tt.dir, got, tt.want)
+					}
+				}
+			})
+
+			t.Run("Move", func(t *testing.T) {
+				put(t, s, "staging/x.pdf", "staged")
+				if err := s.Move(ctx, "staging/x.pdf", "blobs/ab/x.pdf"); err != nil {
+					t.Fatalf("Move: %v", err)
+				}
+				if _, err := s.Stat(ctx, "staging/x.pdf"); !errors.Is(err, ErrNotExist) {
+					t.Errorf("sumber masih ada setelah Move: %v", err)
+				}
+				if info, err := s.Stat(ctx, "blobs/ab/x.pdf"); err != nil || info.Size != 6 {
+					t.Errorf("Stat tujuan = %+v, %v", info, err)
+				}
+				if err := s.Move(ctx, "staging/tidak-ada.pdf", "blobs/ab/y.pdf"); !errors.Is(err, ErrNotExist) {
+					t.Errorf("Move object yang tidak ada error = %v, want ErrNotExist", err)
+				}
+			})
+
+			t.Run("Remove", func(t *testing.T) {
+				tests := []struct {
+					key     string
+					wantErr error
+				}{
+					{"a.pdf", nil},
+					{"a.pdf", nil}, // sudah terhapus, tidak error
+					{"tidak-ada.pdf", nil},
+					{"../luar.pdf", ErrInvalidKey},
+				}
+				for _, tt := range tests {
+					if err := s.Remove(ctx, tt.key); !errors.Is(err, tt.wantErr) {
+						t.Errorf("Remove(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+					}
+				}
+				if _, err := s.Stat(ctx, "a.pdf"); !errors.Is(err, ErrNotExist) {
+					t.Errorf("Stat setelah Remove error = %v, want ErrNotExist", err)
+				}
+			})
+
+			t.Run("RemoveAll", func(t *testing.T) {
+				if err := s.RemoveAll(ctx, "split/doc1"); err != nil {
+					t.Fatalf("RemoveAll: %v", err)
+				}
+				objects, _ := s.List(ctx, "split")
+				if got := keys(objects); strings.Join(got, ",") != "split/doc10/1.pdf" {
+					t.Errorf("List setelah RemoveAll = %v", got)
+				}
+			})
+
+			t.Run("Check", func(t *testing.T) {
+				if err := s.Check(ctx); err != nil {
+					t.Errorf("Check: %v", err)
+				}
+			})
+		})
+	}
+}
+
+func keys(objects []ObjectInfo) []string {
+	var out []string
+	for _, o := range objects {
+		out = append(out, o.Key)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -1,29 +1,63 @@
 package utils
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"repository-un/internal/storage"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 )
 
 // ValidatePDF memeriksa apakah file PDF valid dan tidak rusak
-func ValidatePDF(filePath string) error {
+func ValidatePDF(ctx context.Context, store storage.Storage, key string) error {
+	f, _, err := store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
 	conf := model.NewDefaultConfiguration()
-	return api.ValidateFile(filePath, conf)
+	return api.Validate(f, conf)
 }
 
 // GetPDFPageCount mengembalikan jumlah halaman dalam file PDF
-func GetPDFPageCount(filePath string) (int, error) {
-	return api.PageCountFile(filePath)
+func GetPDFPageCount(ctx context.Context, store storage.Storage, key string) (int, error) {
+	f, _, err := store.Open(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return api.PageCount(f, nil)
 }
 
 // SplitPDF memecah file PDF menjadi satu file per halaman
-// Hasilnya disimpan di outputDir
-func SplitPDF(filePath string, outputDir string) error {
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return err
+// Hasilnya disimpan di outputDir pada storage yang sama
+// dengan nama <nama file>_<halaman>.pdf. Mengembalikan jumlah halaman.
+func SplitPDF(ctx context.Context, store storage.Storage, key string, outputDir string) (int, error) {
+	f, _, err := store.Open(ctx, key)
+	if err != nil {
+		return 0, err
 	}
+	defer f.Close()
+
 	// Split file menjadi single pages
-	return api.SplitFile(filePath, outputDir, 1, nil)
+	spans, err := api.SplitRaw(f, 1, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	base := strings.TrimSuffix(path.Base(key), path.Ext(key))
+	for _, span := range spans {
+		pageKey := storage.Join(outputDir, fmt.Sprintf("%s_%d.pdf", base, span.From))
+		if err := store.Put(ctx, pageKey, span.Reader, -1, "application/pdf"); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(spans), nil
 }
@@ -0,0 +1,351 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"repository-un/internal/storage"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// ExtractPDFText mengambil teks dari setiap halaman PDF.
+// Index slice hasil = nomor halaman - 1.
+//
+// Teks dibaca dari operator Tj/TJ/'/" pada content stream halaman dan
+// di-decode sebagai PDFDocEncoding/Latin-1 (atau UTF-16BE jika ada BOM).
+// Font CID tanpa mapping Unicode bisa menghasilkan teks yang tidak terbaca;
+// karakter yang tidak printable dibuang.
+func ExtractPDFText(ctx context.Context, store storage.Storage, key string) ([]string, error) {
+	f, _, err := store.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf := model.NewDefaultConfiguration()
+	pdfCtx, err := api.ReadAndValidate(f, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]string, 0, pdfCtx.PageCount)
+	for i := 1; i <= pdfCtx.PageCount; i++ {
+		r, err := pdfcpu.ExtractPageContent(pdfCtx, i)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, contentText(content))
+	}
+
+	return pages, nil
+}
+
+// contentText mengambil teks dari satu content stream PDF
+func contentText(content []byte) string {
+	var out strings.Builder
+	var operands [][]byte
+	inText := false
+
+	lex := &contentLexer{data: content}
+	for {
+		tok, kind := lex.next()
+		if kind == tokEOF {
+			break
+		}
+
+		switch kind {
+		case tokString:
+			operands = append(operands, tok)
+			continue
+		case tokArrayGap:
+			// Spasi besar di array TJ biasanya adalah pemisah kata
+			operands = append(operands, []byte(" "))
+			continue
+		case tokOther:
+			continue
+		}
+
+		// tokOperator
+		switch string(tok) {
+		case "BT":
+			inText = true
+		case "ET":
+			inText = false
+			out.WriteByte('\n')
+		case "Tj", "TJ", "'", "\"":
+			if inText {
+				if string(tok) != "Tj" && string(tok) != "TJ" {
+					out.WriteByte('\n')
+				}
+				for _, s := range operands {
+					out.WriteString(decodePDFString(s))
+				}
+			}
+		case "Td", "TD", "T*", "Tm":
+			if inText {
+				out.WriteByte(' ')
+			}
+		case "BI":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+
+	return strings.Join(strings.Fields(out.String()), " ")
+}
+
+// decodePDFString mengubah byte string PDF menjadi teks UTF-8
+func decodePDFString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		u := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return cleanText(string(utf16.Decode(u)))
+	}
+
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return cleanText(string(runes))
+}
+
+// cleanText membuang karakter kontrol yang tidak bisa diindex
+func cleanText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokString
+	tokArrayGap
+	tokOperator
+	tokOther
+)
+
+// contentLexer adalah tokenizer sederhana untuk content stream PDF.
+// Hanya token yang dibutuhkan untuk ekstraksi teks yang dibedakan.
+type contentLexer struct {
+	data    []byte
+	pos     int
+	inArray bool
+}
+
+func (l *contentLexer) next() ([]byte, tokenKind) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, tokEOF
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.literalString(), tokString
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return nil, tokOther
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return nil, tokOther
+	case c == '<':
+		return l.hexString(), tokString
+	case c == '[':
+		l.pos++
+		l.inArray = true
+		return nil, tokOther
+	case c == ']':
+		l.pos++
+		l.inArray = false
+		return nil, tokOther
+	case c == '/':
+		l.pos++
+		l.regular()
+		return nil, tokOther
+	case c == '%':
+		for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+			l.pos++
+		}
+		return nil, tokOther
+	case c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return nil, tokOther
+	}
+
+	tok := l.regular()
+	if len(tok) == 0 {
+		l.pos++
+		return nil, tokOther
+	}
+
+	if n, err := strconv.ParseFloat(string(tok), 64); err == nil {
+		// Kerning di array TJ dalam 1/1000 em, nilai negatif besar = spasi
+		if l.inArray && n < -200 {
+			return nil, tokArrayGap
+		}
+		return nil, tokOther
+	}
+
+	return tok, tokOperator
+}
+
+func (l *contentLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *contentLexer) skipSpace() {
+	for l.pos < len(l.data) && isPDFSpace(l.data[l.pos]) {
+		l.pos++
+	}
+}
+
+// regular membaca token sampai delimiter atau whitespace
+func (l *contentLexer) regular() []byte {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return l.data[start:l.pos]
+}
+
+// literalString membaca string (...) termasuk escape dan kurung bersarang
+func (l *contentLexer) literalString() []byte {
+	var out []byte
+	depth := 0
+	l.pos++ // lewati '('
+
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+				// diabaikan
+			case '\r', '\n':
+				// line continuation
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// hexString membaca string <...>
+func (l *contentLexer) hexString() []byte {
+	l.pos++ // lewati '<'
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // lewati '>'
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[i*2:i*2+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+// skipInlineImage melewati data biner inline image (BI ... ID <data> EI)
+func (l *contentLexer) skipInlineImage() {
+	idx := strings.Index(string(l.data[l.pos:]), "ID")
+	if idx < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += idx + 2
+
+	for l.pos+2 < len(l.data) {
+		if isPDFSpace(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 >= len(l.data) || isPDFSpace(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,137 @@
+package utils
+
+import "testing"
+
+func TestContentText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Tj", "BT /F1 12 Tf 72 712 Td (Hello World) Tj ET", "Hello World"},
+		{"TJ kerning", "BT [(Hel) -20 (lo)] TJ ET", "Hello"},
+		{"TJ word gap", "BT [(Hello) -500 (World)] TJ ET", "Hello World"},
+		{"quote", "BT (Baris1) Tj (Baris2) ' ET", "Baris1 Baris2"},
+		{"double quote", `BT (Baris1) Tj 1 2 (Baris2) " ET`, "Baris1 Baris2"},
+		{"positioning", "BT (A) Tj 0 -14 Td (B) Tj T* (C) Tj ET", "A B C"},
+		{"outside text object", "(Tersembunyi) Tj BT (Terlihat) Tj ET", "Terlihat"},
+		{"multiple text objects", "BT (Satu) Tj ET BT (Dua) Tj ET", "Satu Dua"},
+		{"octal", `BT (Caf\351 \101\102C) Tj ET`, "Café ABC"},
+		{"escaped parentheses", `BT (f\(x\) = \\y) Tj ET`, `f(x) = \y`},
+		{"nested parentheses", "BT (a (b) c) Tj ET", "a (b) c"},
+		{"escaped whitespace", `BT (kata\tkata\nbaris) Tj ET`, "kata kata baris"},
+		{"line continuation", "BT (sambung\\\nan) Tj ET", "sambungan"},
+		{"hex", "BT <48656C6C6F> Tj ET", "Hello"},
+		{"hex with spaces and odd length", "BT <48 49 4> Tj ET", "HI@"},
+		{"utf-16be", "BT <FEFF004A00FC007200670065006E> Tj ET", "Jürgen"},
+		{"utf-16be literal", `BT (\376\377\000O\000K) Tj ET`, "OK"},
+		{"dictionary operand", "BT /Span <</ActualText (x)>> BDC (Isi) Tj EMC ET", "Isi"},
+		{"comment", "% (Komentar) Tj\nBT (Teks) Tj ET", "Teks"},
+		{"inline image", "BT (Sebelum) Tj ET BI /W 2 /H 2 /BPC 8 ID \x00(Tj)\xff) Tj EI BT (Sesudah) Tj ET", "Sebelum Sesudah"},
+		{"inline image EI in data", "BI /W 1 ID xEIx) Tj\n EI BT (Sesudah) Tj ET", "Sesudah"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentText([]byte(tt.content)); got != tt.want {
+				t.Errorf("contentText(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+// Content stream yang rusak atau terpotong tidak boleh membuat panic
+func TestContentTextMalformed(t *testing.T) {
+	tests := []string{
+		"BT (tidak ditutup Tj",
+		"BT (escape di akhir\\",
+		"BT (oktal \\7",
+		"BT <48656C",
+		"BT <",
+		"BT [(A) -300",
+		"BI /W 1 /H 1 ID",
+		"BI /W 1 /H 1",
+		"BI",
+		"BT (A) Tj BI ID \x00\x01 E",
+		"]]]>>>)))}}}{{{",
+		"<<<<>>>>",
+		"/",
+		"%",
+		"\x00\x00\xff\xfe",
+		"BT [(A) -1e999 (B)] TJ ET",
+		"ET ET Tj TJ ' \"",
+	}
+	for _, content := range tests {
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					t.Errorf("contentText(%q) panic: %v", content, p)
+				}
+			}()
+			contentText([]byte(content))
+		}()
+	}
+}
+
+func FuzzContentText(f *testing.F) {
+	f.Add([]byte("BT [(Hel) -500 (lo)] TJ <FEFF0041> Tj ET"))
+	f.Add([]byte("BI /W 1 ID \x00 EI BT (a\\(b\\)\\101) ' ET"))
+	f.Fuzz(func(t *testing.T, content []byte) {
+		contentText(content)
+	})
+}
+
+func TestDecodePDFString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"ascii", []byte("Repository"), "Repository"},
+		{"latin-1", []byte{'S', 0xE9, 'a', 'n', 'c', 'e'}, "Séance"},
+		{"control characters dropped", []byte{'a', 0x01, 0x7F, 'b'}, "ab"},
+		{"whitespace normalized", []byte("a\tb\nc"), "a b c"},
+		{"utf-16be", []byte{0xFE, 0xFF, 0x00, 'I', 0x00, 'D', 0x00, 0xE9}, "IDé"},
+		{"utf-16be surrogate pair", []byte{0xFE, 0xFF, 0xD8, 0x3D, 0xDE, 0x00}, "😀"},
+		{"utf-16be odd length", []byte{0xFE, 0xFF, 0x00, 'A', 0x00}, "A"},
+		{"bom only", []byte{0xFE, 0xFF}, ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodePDFString(tt.in); got != tt.want {
+				t.Errorf("decodePDFString(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentLexer(t *testing.T) {
+	lex := &contentLexer{data: []byte("/F1 12 Tf [(a) -250 (b) 10] TJ <41> (x\\)y)")}
+
+	type token struct {
+		text string
+		kind tokenKind
+	}
+	want := []token{
+		{"", tokOther}, // /F1
+		{"", tokOther}, // 12
+		{"Tf", tokOperator},
+		{"", tokOther}, // [
+		{"a", tokString},
+		{"", tokArrayGap}, // -250
+		{"b", tokString},
+		{"", tokOther}, // 10
+		{"", tokOther}, // ]
+		{"TJ", tokOperator},
+		{"A", tokString},
+		{"x)y", tokString},
+		{"", tokEOF},
+	}
+	for i, w := range want {
+		tok, kind := lex.next()
+		if string(tok) != w.text || kind != w.kind {
+			t.Fatalf("token %d = %q (%d), want %q (%d)", i, tok, kind, w.text, w.kind)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"repository-un/internal/storage"
+
+	"golang.org/x/image/draw"
+)
+
+// Ukuran gambar halaman (sisi terpanjang, piksel)
+const (
+	ImageSizeThumb  = "thumb"
+	ImageSizeMedium = "medium"
+)
+
+// ImageSizes adalah ukuran gambar halaman yang dibuat saat render
+var ImageSizes = map[string]int{
+	ImageSizeThumb:  240,
+	ImageSizeMedium: 1024,
+}
+
+// ImageFormats adalah format gambar halaman yang didukung beserta content type-nya
+var ImageFormats = map[string]string{
+	"jpg": "image/jpeg",
+	"png": "image/png",
+}
+
+// ErrRendererNotFound dikembalikan jika program pdftoppm tidak tersedia
+var ErrRendererNotFound = errors.New("pdftoppm tidak ditemukan")
+
+// RenderOptions mengatur render halaman PDF menjadi gambar
+type RenderOptions struct {
+	// Pdftoppm adalah path program pdftoppm (poppler-utils)
+	Pdftoppm string
+
+	// Format adalah "jpg" atau "png"
+	Format string
+}
+
+// ImageKey mengembalikan lokasi gambar halaman di dalam folder split versi
+func ImageKey(splitDir, size string, page int, format string) string {
+	return storage.Join(splitDir, "images", size, fmt.Sprintf("%d.%s", page, format))
+}
+
+// RenderPDFPages merender setiap halaman PDF menjadi gambar ukuran medium
+// dan thumbnail, lalu menyimpannya ke storage di bawah outputDir
+// (lihat ImageKey). Mengembalikan jumlah halaman yang dirender.
+func RenderPDFPages(ctx context.Context, store storage.Storage, key string, outputDir string, opts RenderOptions) (int, error) {
+	if _, ok := ImageFormats[opts.Format]; !ok {
+		return 0, fmt.Errorf("format gambar tidak didukung: %s", opts.Format)
+	}
+
+	bin, err := exec.LookPath(opts.Pdftoppm)
+	if err != nil {
+		return 0, ErrRendererNotFound
+	}
+
+	// pdftoppm butuh file lokal, storage bisa saja S3
+	tmpDir, err := os.MkdirTemp("", "render-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	input := filepath.Join(tmpDir, "input.pdf")
+	if err := copyToFile(ctx, store, key, input); err != nil {
+		return 0, err
+	}
+
+	flag := "-jpeg"
+	if opts.Format == "png" {
+		flag = "-png"
+	}
+
+	prefix := filepath.Join(tmpDir, "page")
+	cmd := exec.CommandContext(ctx, bin, flag, "-scale-to", strconv.Itoa(ImageSizes[ImageSizeMedium]), input, prefix)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("pdftoppm gagal: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	pages, err := renderedPages(tmpDir, opts.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	for page, file := range pages {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 0, err
+		}
+
+		mediumKey := ImageKey(outputDir, ImageSizeMedium, page, opts.Format)
+		err = store.Put(ctx, mediumKey, bytes.NewReader(data), int64(len(data)), ImageFormats[opts.Format])
+		if err != nil {
+			return 0, err
+		}
+
+		thumb, err := resizeImage(data, ImageSizes[ImageSizeThumb], opts.Format)
+		if err != nil {
+			return 0, fmt.Errorf("gagal membuat thumbnail halaman %d: %w", page, err)
+		}
+
+		thumbKey := ImageKey(outputDir, ImageSizeThumb, page, opts.Format)
+		err = store.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), ImageFormats[opts.Format])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(pages), nil
+}
+
+// copyToFile menyalin object storage ke file lokal
+func copyToFile(ctx context.Context, store storage.Storage, key, dst string) error {
+	src, _, err := store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, src); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// renderedPages mencari file hasil pdftoppm. Nama file berbentuk
+// page-1.jpg atau page-01.jpg (lebar nomor mengikuti jumlah halaman).
+func renderedPages(dir, format string) (map[int]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := map[int]string{}
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "page-") || !strings.HasSuffix(name, "."+format) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "page-"), "."+format))
+		if err != nil {
+			continue
+		}
+		pages[n] = filepath.Join(dir, name)
+	}
+
+	if len(pages) == 0 {
+		return nil, errors.New("pdftoppm tidak menghasilkan gambar")
+	}
+	return pages, nil
+}
+
+// resizeImage mengecilkan gambar sehingga sisi terpanjangnya maxSide piksel
+func resizeImage(data []byte, maxSide int, format string) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w >= h && w > maxSide {
+		w, h = maxSide, h*maxSide/w
+	} else if h > w && h > maxSide {
+		w, h = w*maxSide/h, maxSide
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80})
+	}
+	return buf.Bytes(), err
+}
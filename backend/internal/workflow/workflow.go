@@ -0,0 +1,141 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status dokumen dalam alur editorial
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusRejected  = "rejected"
+	StatusArchived  = "archived"
+)
+
+// Action adalah nama transisi yang dipanggil lewat endpoint
+// POST /api/documents/:id/<action>
+const (
+	ActionSubmit      = "submit"
+	ActionWithdraw    = "withdraw"
+	ActionStartReview = "start-review"
+	ActionPublish     = "publish"
+	ActionReject      = "reject"
+	ActionArchive     = "archive"
+	ActionReopen      = "reopen"
+)
+
+// ErrUnknownAction dikembalikan jika nama aksi tidak dikenal
+var ErrUnknownAction = errors.New("aksi tidak dikenal")
+
+// ErrCommentRequired dikembalikan jika transisi wajib disertai komentar
+var ErrCommentRequired = errors.New("komentar wajib diisi untuk aksi ini")
+
+// ErrForbidden dikembalikan jika pemanggil tidak boleh menjalankan transisi
+var ErrForbidden = errors.New("anda tidak memiliki hak untuk aksi ini")
+
+// InvalidTransitionError dikembalikan jika aksi tidak berlaku untuk status sekarang
+type InvalidTransitionError struct {
+	Action string
+	From   string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("aksi %q tidak dapat dilakukan pada dokumen berstatus %q", e.Action, e.From)
+}
+
+// Transition mendefinisikan satu perpindahan status
+type Transition struct {
+	Action string
+	From   []string
+	To     string
+
+	// OwnerAllowed berarti pemilik dokumen boleh menjalankan transisi ini.
+	// Admin (reviewer) selalu boleh.
+	OwnerAllowed bool
+
+	// CommentRequired berarti komentar reviewer wajib diisi
+	CommentRequired bool
+}
+
+// Transitions adalah seluruh state machine status dokumen:
+//
+//	submit       : draft, rejected            → submitted (pemilik, admin)
+//	withdraw     : submitted                  → draft     (pemilik, admin)
+//	start-review : submitted                  → in_review (admin)
+//	publish      : in_review                  → published (admin)
+//	reject       : submitted, in_review       → rejected  (admin, wajib komentar)
+//	archive      : draft, rejected, published → archived  (pemilik, admin)
+//	reopen       : archived                   → draft     (admin)
+var Transitions = []Transition{
+	{Action: ActionSubmit, From: []string{StatusDraft, StatusRejected}, To: StatusSubmitted, OwnerAllowed: true},
+	{Action: ActionWithdraw, From: []string{StatusSubmitted}, To: StatusDraft, OwnerAllowed: true},
+	{Action: ActionStartReview, From: []string{StatusSubmitted}, To: StatusInReview},
+	{Action: ActionPublish, From: []string{StatusInReview}, To: StatusPublished},
+	{Action: ActionReject, From: []string{StatusSubmitted, StatusInReview}, To: StatusRejected, CommentRequired: true},
+	{Action: ActionArchive, From: []string{StatusDraft, StatusRejected, StatusPublished}, To: StatusArchived, OwnerAllowed: true},
+	{Action: ActionReopen, From: []string{StatusArchived}, To: StatusDraft},
+}
+
+// Editable mengecek apakah file, metadata dan versi aktif dokumen dengan
+// status ini boleh diubah. Dokumen yang sedang direview atau sudah terbit
+// dikunci agar yang terbit selalu isi yang sudah direview; ubah dulu
+// statusnya (reject, atau archive lalu reopen) untuk mengeditnya.
+func Editable(status string) bool {
+	return status != StatusInReview && status != StatusPublished
+}
+
+// IsAction mengecek apakah nama termasuk aksi workflow
+func IsAction(name string) bool {
+	for _, t := range Transitions {
+		if t.Action == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve mencari transisi untuk aksi dari status sekarang dan memeriksa
+// hak pemanggil serta kelengkapan komentar
+func Resolve(action, from string, isOwner, isAdmin bool, comment string) (Transition, error) {
+	found := false
+	for _, t := range Transitions {
+		if t.Action != action {
+			continue
+		}
+		found = true
+
+		for _, f := range t.From {
+			if f != from {
+				continue
+			}
+			if !isAdmin && !(t.OwnerAllowed && isOwner) {
+				return t, ErrForbidden
+			}
+			if t.CommentRequired && comment == "" {
+				return t, ErrCommentRequired
+			}
+			return t, nil
+		}
+	}
+
+	if !found {
+		return Transition{}, ErrUnknownAction
+	}
+	return Transition{}, &InvalidTransitionError{Action: action, From: from}
+}
+
+// Available mengembalikan aksi yang bisa dijalankan pemanggil dari status sekarang
+func Available(from string, isOwner, isAdmin bool) []string {
+	actions := []string{}
+	for _, t := range Transitions {
+		for _, f := range t.From {
+			if f == from && (isAdmin || (t.OwnerAllowed && isOwner)) {
+				actions = append(actions, t.Action)
+			}
+		}
+	}
+	return actions
+}
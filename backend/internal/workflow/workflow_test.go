@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		action  string
+		from    string
+		isOwner bool
+		isAdmin bool
+		comment string
+		wantTo  string
+		wantErr error
+	}{
+		{ActionSubmit, StatusDraft, true, false, "", StatusSubmitted, nil},
+		{ActionSubmit, StatusRejected, true, false, "", StatusSubmitted, nil},
+		{ActionSubmit, StatusDraft, false, false, "", "", ErrForbidden},
+		{ActionWithdraw, StatusSubmitted, true, false, "", StatusDraft, nil},
+		{ActionStartReview, StatusSubmitted, true, false, "", "", ErrForbidden},
+		{ActionStartReview, StatusSubmitted, false, true, "", StatusInReview, nil},
+		{ActionPublish, StatusInReview, false, true, "", StatusPublished, nil},
+		{ActionPublish, StatusInReview, true, false, "", "", ErrForbidden},
+		{ActionReject, StatusInReview, false, true, "", "", ErrCommentRequired},
+		{ActionReject, StatusSubmitted, false, true, "kurang abstrak", StatusRejected, nil},
+		{ActionArchive, StatusPublished, true, false, "", StatusArchived, nil},
+		{ActionReopen, StatusArchived, true, false, "", "", ErrForbidden},
+		{ActionReopen, StatusArchived, false, true, "", StatusDraft, nil},
+		{"delete", StatusDraft, false, true, "", "", ErrUnknownAction},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(tt.action, tt.from, tt.isOwner, tt.isAdmin, tt.comment)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Resolve(%s, %s, owner=%v, admin=%v) error = %v, want %v",
+				tt.action, tt.from, tt.isOwner, tt.isAdmin, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.To != tt.wantTo {
+			t.Errorf("Resolve(%s, %s) = %s, want %s", tt.action, tt.from, got.To, tt.wantTo)
+		}
+	}
+}
+
+func TestResolveInvalidTransition(t *testing.T) {
+	invalid := []struct{ action, from string }{
+		{ActionPublish, StatusDraft},
+		{ActionPublish, StatusSubmitted},
+		{ActionSubmit, StatusPublished},
+		{ActionWithdraw, StatusInReview},
+		{ActionArchive, StatusInReview},
+		{ActionReopen, StatusPublished},
+	}
+	for _, tt := range invalid {
+		_, err := Resolve(tt.action, tt.from, true, true, "komentar")
+		var invalidErr *InvalidTransitionError
+		if !errors.As(err, &invalidErr) || invalidErr.Action != tt.action || invalidErr.From != tt.from {
+			t.Errorf("Resolve(%s, %s) error = %v, want InvalidTransitionError", tt.action, tt.from, err)
+		}
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		from             string
+		isOwner, isAdmin bool
+		want             []string
+	}{
+		{StatusDraft, true, false, []string{ActionSubmit, ActionArchive}},
+		{StatusSubmitted, true, false, []string{ActionWithdraw}},
+		{StatusSubmitted, false, true, []string{ActionWithdraw, ActionStartReview, ActionReject}},
+		{StatusInReview, true, false, []string{}},
+		{StatusPublished, false, false, []string{}},
+		{StatusArchived, false, true, []string{ActionReopen}},
+	}
+	for _, tt := range tests {
+		if got := Available(tt.from, tt.isOwner, tt.isAdmin); !slices.Equal(got, tt.want) {
+			t.Errorf("Available(%s, owner=%v, admin=%v) = %v, want %v", tt.from, tt.isOwner, tt.isAdmin, got, tt.want)
+		}
+	}
+}
+
+func TestEditable(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{StatusDraft, true},
+		{StatusSubmitted, true},
+		{StatusRejected, true},
+		{StatusArchived, true},
+		{StatusInReview, false},
+		{StatusPublished, false},
+	}
+	for _, tt := range tests {
+		if got := Editable(tt.status); got != tt.want {
+			t.Errorf("Editable(%s) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
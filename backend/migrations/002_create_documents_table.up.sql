@@ -0,0 +1,11 @@
+-- Create documents table
+-- Bentuk awal tabel documents; kolom lain ditambahkan migration berikutnya
+CREATE TABLE IF NOT EXISTS documents (
+    id UUID PRIMARY KEY,
+    judul TEXT NOT NULL,
+    penulis TEXT NOT NULL,
+    jenis_file VARCHAR(20) NOT NULL,
+    file_path TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+ALTER TABLE documents DROP COLUMN IF EXISTS current_version_id;
+DROP TABLE IF EXISTS document_versions;
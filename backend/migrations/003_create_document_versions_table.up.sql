@@ -0,0 +1,32 @@
+-- Create document_versions table
+-- Setiap file yang pernah dilampirkan ke dokumen disimpan sebagai satu versi
+CREATE TABLE IF NOT EXISTS document_versions (
+    id UUID PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    version_number INTEGER NOT NULL,
+    file_path TEXT NOT NULL,
+    original_name TEXT NOT NULL DEFAULT '',
+    file_size BIGINT NOT NULL DEFAULT 0,
+    split_dir TEXT NOT NULL DEFAULT '',
+    page_count INTEGER NOT NULL DEFAULT 0,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (document_id, version_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_document_id ON document_versions(document_id);
+
+-- Versi yang sedang aktif untuk setiap dokumen
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS current_version_id UUID REFERENCES document_versions(id) ON DELETE SET NULL;
+
+-- Dokumen lama dicatat sebagai versi 1 dengan hasil split di split/<id>
+INSERT INTO document_versions (id, document_id, version_number, file_path, split_dir, created_at)
+SELECT gen_random_uuid(), d.id, 1, d.file_path, 'split/' || d.id, d.created_at
+FROM documents d
+WHERE NOT EXISTS (SELECT 1 FROM document_versions v WHERE v.document_id = d.id);
+
+UPDATE documents d
+SET current_version_id = v.id
+FROM document_versions v
+WHERE v.document_id = d.id AND v.version_number = 1 AND d.current_version_id IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_documents_created_at;
+DROP INDEX IF EXISTS idx_documents_status;
+DROP INDEX IF EXISTS idx_documents_jenis_file;
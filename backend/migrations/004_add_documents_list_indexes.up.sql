@@ -0,0 +1,4 @@
+-- Index untuk pagination, sorting dan filter GET /api/documents
+CREATE INDEX IF NOT EXISTS idx_documents_created_at ON documents(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_documents_status ON documents(status);
+CREATE INDEX IF NOT EXISTS idx_documents_jenis_file ON documents(jenis_file);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS document_page_texts;
+DROP INDEX IF EXISTS idx_documents_search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,23 @@
+-- Full-text search untuk dokumen
+-- Memakai konfigurasi 'simple' karena PostgreSQL belum punya stemmer Bahasa Indonesia
+
+-- Vektor pencarian dari metadata (judul lebih berbobot dari penulis)
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(judul, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(penulis, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN(search_vector);
+
+-- Teks hasil ekstraksi PDF per halaman untuk setiap versi file
+CREATE TABLE IF NOT EXISTS document_page_texts (
+    version_id UUID NOT NULL REFERENCES document_versions(id) ON DELETE CASCADE,
+    page_number INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED,
+    PRIMARY KEY (version_id, page_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_page_texts_search_vector ON document_page_texts USING GIN(search_vector);
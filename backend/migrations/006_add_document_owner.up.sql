@@ -0,0 +1,6 @@
+-- Pemilik dokumen (user yang mengupload)
+-- Dokumen lama tidak punya pemilik dan hanya bisa diubah oleh admin
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS owner_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_owner_id ON documents(owner_id);
@@ -0,0 +1,4 @@
+-- Status lama yang dinormalkan ke draft tidak bisa dikembalikan
+DROP TABLE IF EXISTS document_status_history;
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_status_check;
+ALTER TABLE documents ALTER COLUMN status DROP DEFAULT;
@@ -0,0 +1,31 @@
+-- Alur editorial status dokumen:
+-- draft -> submitted -> in_review -> published / rejected, serta archived
+
+-- Status lama yang tidak dikenal dikembalikan ke draft
+UPDATE documents SET status = lower(status)
+WHERE lower(status) IN ('draft', 'submitted', 'in_review', 'published', 'rejected', 'archived');
+
+UPDATE documents SET status = 'draft'
+WHERE status NOT IN ('draft', 'submitted', 'in_review', 'published', 'rejected', 'archived');
+
+ALTER TABLE documents ALTER COLUMN status TYPE VARCHAR(20);
+ALTER TABLE documents ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_status_check;
+ALTER TABLE documents ADD CONSTRAINT documents_status_check
+    CHECK (status IN ('draft', 'submitted', 'in_review', 'published', 'rejected', 'archived'));
+
+-- Riwayat perpindahan status: siapa, kapan, dan komentar reviewer
+CREATE TABLE IF NOT EXISTS document_status_history (
+    id UUID PRIMARY KEY,
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_status_history_document_id
+    ON document_status_history(document_id, created_at);
@@ -0,0 +1,6 @@
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_processing_status_check;
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS processing_status,
+    DROP COLUMN IF EXISTS processing_error;
+
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,31 @@
+-- Antrian job background (pemrosesan PDF, dll)
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'running', 'done', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Worker mencari job queued yang sudah waktunya dijalankan
+CREATE INDEX IF NOT EXISTS idx_jobs_queued ON jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_jobs_status_updated_at ON jobs(status, updated_at);
+
+-- Status pemrosesan file versi aktif dokumen (validasi, split, index teks)
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS processing_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS processing_error TEXT;
+
+-- Dokumen lama sudah diproses saat upload
+UPDATE documents SET processing_status = 'ready' WHERE processing_status = 'pending';
+
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_processing_status_check;
+ALTER TABLE documents ADD CONSTRAINT documents_processing_status_check
+    CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed'));
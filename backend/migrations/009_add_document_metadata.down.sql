@@ -0,0 +1,24 @@
+-- Pencarian kembali hanya memakai judul dan penulis
+DROP INDEX IF EXISTS idx_documents_search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE documents
+    ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(judul, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(penulis, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN(search_vector);
+
+DROP TABLE IF EXISTS document_identifiers;
+DROP TABLE IF EXISTS document_keywords;
+DROP TABLE IF EXISTS document_authors;
+
+DROP INDEX IF EXISTS idx_documents_year;
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS abstract,
+    DROP COLUMN IF EXISTS year,
+    DROP COLUMN IF EXISTS faculty,
+    DROP COLUMN IF EXISTS department,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS publisher;
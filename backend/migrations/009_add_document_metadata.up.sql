@@ -0,0 +1,63 @@
+-- Metadata dokumen mengikuti Dublin Core
+-- (title, creator, contributor, subject, description, date, publisher, language, identifier)
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS abstract TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS year INTEGER CHECK (year BETWEEN 1900 AND 2100),
+    ADD COLUMN IF NOT EXISTS faculty VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS department VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS language VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS publisher VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_documents_year ON documents(year);
+
+-- Penulis dan pembimbing berurutan (dc:creator / dc:contributor).
+-- Kolom documents.penulis tetap diisi gabungan nama penulis untuk tampilan list.
+CREATE TABLE IF NOT EXISTS document_authors (
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('author', 'advisor')),
+    position INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (document_id, role, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_authors_name ON document_authors(lower(name));
+
+-- Kata kunci (dc:subject)
+CREATE TABLE IF NOT EXISTS document_keywords (
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    keyword VARCHAR(100) NOT NULL,
+    PRIMARY KEY (document_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_keywords_keyword ON document_keywords(lower(keyword));
+
+-- Identifier (dc:identifier): DOI, ISBN, ISSN, handle, URL, NIM, dll
+CREATE TABLE IF NOT EXISTS document_identifiers (
+    document_id UUID NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    scheme VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (document_id, scheme, value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_identifiers_value ON document_identifiers(scheme, value);
+
+-- Penulis dokumen lama dicatat sebagai penulis pertama
+INSERT INTO document_authors (document_id, role, position, name)
+SELECT id, 'author', 1, penulis
+FROM documents d
+WHERE penulis <> ''
+  AND NOT EXISTS (SELECT 1 FROM document_authors a WHERE a.document_id = d.id);
+
+-- Abstrak ikut dicari dengan bobot lebih rendah dari judul dan penulis
+DROP INDEX IF EXISTS idx_documents_search_vector;
+ALTER TABLE documents DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE documents
+    ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(judul, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(penulis, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(abstract, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_documents_search_vector ON documents USING GIN(search_vector);
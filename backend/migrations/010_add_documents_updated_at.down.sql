@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_documents_updated_at;
+ALTER TABLE documents DROP COLUMN IF EXISTS updated_at;
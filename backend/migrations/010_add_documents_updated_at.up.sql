@@ -0,0 +1,8 @@
+-- Waktu perubahan terakhir metadata, status atau file dokumen.
+-- Dipakai sebagai datestamp untuk harvesting OAI-PMH.
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE documents SET updated_at = created_at WHERE created_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_documents_updated_at ON documents(updated_at, id);
@@ -0,0 +1,31 @@
+-- Sesi upload bertahap (resumable). File dikirim per potongan lalu
+-- digabung saat complete menjadi dokumen baru atau versi baru dokumen
+-- document_id. Potongan disimpan di storage "chunks/<id>/<nomor>".
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_id UUID REFERENCES documents(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    total_size BIGINT NOT NULL CHECK (total_size > 0),
+    chunk_size BIGINT NOT NULL CHECK (chunk_size > 0),
+    sha256 CHAR(64),
+    -- TRUE selama potongan sedang digabung, mencegah complete ganda
+    completing BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_owner_id ON upload_sessions(owner_id);
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at);
+
+-- Potongan yang sudah diterima beserta checksum SHA-256 yang sudah dicek
+CREATE TABLE IF NOT EXISTS upload_chunks (
+    session_id UUID NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL CHECK (number > 0),
+    size BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, number)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE documents DROP COLUMN IF EXISTS mime_type;
+ALTER TABLE document_versions DROP COLUMN IF EXISTS mime_type;
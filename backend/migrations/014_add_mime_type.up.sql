@@ -0,0 +1,26 @@
+-- MIME type file hasil deteksi isi saat upload, dipakai sebagai
+-- Content-Type saat download. documents.mime_type mengikuti versi aktif.
+ALTER TABLE document_versions ADD COLUMN IF NOT EXISTS mime_type VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE documents ADD COLUMN IF NOT EXISTS mime_type VARCHAR(255) NOT NULL DEFAULT '';
+
+-- File lama belum pernah dideteksi, perkirakan dari ekstensinya
+UPDATE document_versions SET mime_type = CASE lower(substring(file_path from '\.([A-Za-z0-9]+)$'))
+    WHEN 'pdf' THEN 'application/pdf'
+    WHEN 'docx' THEN 'application/vnd.openxmlformats-officedocument.wordprocessingml.document'
+    WHEN 'odt' THEN 'application/vnd.oasis.opendocument.text'
+    WHEN 'epub' THEN 'application/epub+zip'
+    WHEN 'png' THEN 'image/png'
+    WHEN 'jpg' THEN 'image/jpeg'
+    WHEN 'jpeg' THEN 'image/jpeg'
+    WHEN 'gif' THEN 'image/gif'
+    WHEN 'webp' THEN 'image/webp'
+    WHEN 'tif' THEN 'image/tiff'
+    WHEN 'tiff' THEN 'image/tiff'
+    WHEN 'zip' THEN 'application/zip'
+    ELSE 'application/octet-stream'
+END
+WHERE mime_type = '';
+
+UPDATE documents d SET mime_type = v.mime_type
+FROM document_versions v
+WHERE v.id = d.current_version_id AND d.mime_type = '';
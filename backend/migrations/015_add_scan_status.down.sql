@@ -0,0 +1,12 @@
+UPDATE documents SET processing_status = 'failed' WHERE processing_status = 'quarantined';
+
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_processing_status_check;
+ALTER TABLE documents ADD CONSTRAINT documents_processing_status_check
+    CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed'));
+
+DROP INDEX IF EXISTS idx_document_versions_quarantine;
+ALTER TABLE document_versions DROP CONSTRAINT IF EXISTS document_versions_scan_status_check;
+ALTER TABLE document_versions
+    DROP COLUMN IF EXISTS scan_status,
+    DROP COLUMN IF EXISTS scan_result,
+    DROP COLUMN IF EXISTS scanned_at;
@@ -0,0 +1,22 @@
+-- Hasil pemindaian malware per file versi. File baru bisa didownload
+-- setelah scan_status 'clean'; file 'infected' atau yang gagal dipindai
+-- ('error') dikarantina.
+ALTER TABLE document_versions
+    ADD COLUMN IF NOT EXISTS scan_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS scan_result TEXT,
+    ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMP WITH TIME ZONE;
+
+-- File lama sudah bisa didownload sebelum pemindaian ada
+UPDATE document_versions SET scan_status = 'clean' WHERE scan_status = 'pending';
+
+ALTER TABLE document_versions DROP CONSTRAINT IF EXISTS document_versions_scan_status_check;
+ALTER TABLE document_versions ADD CONSTRAINT document_versions_scan_status_check
+    CHECK (scan_status IN ('pending', 'clean', 'infected', 'error'));
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_quarantine
+    ON document_versions(scanned_at DESC) WHERE scan_status IN ('infected', 'error');
+
+-- Dokumen yang file aktifnya dikarantina
+ALTER TABLE documents DROP CONSTRAINT IF EXISTS documents_processing_status_check;
+ALTER TABLE documents ADD CONSTRAINT documents_processing_status_check
+    CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed', 'quarantined'));
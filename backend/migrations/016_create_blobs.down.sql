@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_document_versions_sha256;
+ALTER TABLE document_versions DROP COLUMN IF EXISTS sha256;
+DROP TABLE IF EXISTS blobs;
@@ -0,0 +1,20 @@
+-- File upload disimpan berdasarkan isinya (content-addressed) di
+-- "blobs/<2 karakter awal sha256>/<sha256><ekstensi>". File yang sama
+-- hanya disimpan sekali dan dipakai bersama oleh beberapa versi.
+-- ref_count adalah jumlah versi yang memakai blob; blob dengan
+-- ref_count 0 dihapus beserta filenya.
+CREATE TABLE IF NOT EXISTS blobs (
+    sha256 CHAR(64) PRIMARY KEY,
+    file_path TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    mime_type VARCHAR(255) NOT NULL DEFAULT '',
+    ref_count INTEGER NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Versi lama (sebelum blob) tetap memakai file masing-masing dengan sha256 NULL
+ALTER TABLE document_versions
+    ADD COLUMN IF NOT EXISTS sha256 CHAR(64) REFERENCES blobs(sha256);
+
+CREATE INDEX IF NOT EXISTS idx_document_versions_sha256
+    ON document_versions(sha256) WHERE sha256 IS NOT NULL;
@@ -0,0 +1,10 @@
+// Package migrations berisi file SQL skema database yang ditanam ke binary
+// server. Nama file: <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql.
+package migrations
+
+import "embed"
+
+// FS berisi semua file migration
+//
+//go:embed *.sql
+var FS embed.FS
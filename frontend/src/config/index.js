@@ -15,6 +15,8 @@ export const API_ENDPOINTS = {
     // Auth
     AUTH_LOGIN: `${API_BASE_URL}/api/auth/login`,
     AUTH_REGISTER: `${API_BASE_URL}/api/auth/register`,
+    AUTH_REFRESH: `${API_BASE_URL}/api/auth/refresh`,
+    AUTH_LOGOUT: `${API_BASE_URL}/api/auth/logout`,
     AUTH_ME: `${API_BASE_URL}/api/auth/me`,
 
     // Users
@@ -24,9 +26,12 @@ export const API_ENDPOINTS = {
     // Documents
     DOCUMENTS: `${API_BASE_URL}/api/documents`,
     DOCUMENT_BY_ID: (id) => `${API_BASE_URL}/api/documents/${id}`,
-    DOCUMENT_PAGES: (id) => `${API_BASE_URL}/api/documents/pages/${id}`,
+    DOCUMENT_PAGES: (id) => `${API_BASE_URL}/api/documents/${id}/pages`,
     DOCUMENT_DOWNLOAD: (id) => `${API_BASE_URL}/download/${id}`,
     DOCUMENT_PREVIEW: (id, page) => `${API_BASE_URL}/preview/split/${id}/${page}`,
+    // Gambar halaman (page mulai dari 1), size: "thumb" atau "medium"
+    DOCUMENT_PAGE_IMAGE: (id, page, size = "thumb") =>
+        `${API_BASE_URL}/preview/image/${id}/${page}?size=${size}`,
 };
 
 // App Configuration
@@ -37,6 +42,7 @@ export const APP_CONFIG = {
     // Storage keys
     TOKEN_KEY: "auth_token",
     USER_KEY: "auth_user",
+    REFRESH_TOKEN_KEY: "auth_refresh_token",
 
     // Pagination
     DEFAULT_PAGE_SIZE: 10,
@@ -23,6 +23,8 @@ class AuthService {
     constructor() {
         this.tokenKey = APP_CONFIG.TOKEN_KEY;
         this.userKey = APP_CONFIG.USER_KEY;
+        this.refreshTokenKey = APP_CONFIG.REFRESH_TOKEN_KEY;
+        this.refreshing = null;
     }
 
     /**
@@ -33,6 +35,14 @@ class AuthService {
         return localStorage.getItem(this.tokenKey);
     }
 
+    /**
+     * Ambil refresh token dari localStorage
+     * @returns {string|null} - Refresh token atau null
+     */
+    getRefreshToken() {
+        return localStorage.getItem(this.refreshTokenKey);
+    }
+
     /**
      * Ambil data user dari localStorage
      * @returns {Object|null} - User object atau null
@@ -61,12 +71,16 @@ class AuthService {
 
     /**
      * Simpan token dan user ke localStorage
-     * @param {string} token - JWT token
+     * @param {string} token - JWT access token
      * @param {Object} user - User object
+     * @param {string} [refreshToken] - Refresh token
      */
-    setAuth(token, user) {
+    setAuth(token, user, refreshToken) {
         localStorage.setItem(this.tokenKey, token);
         localStorage.setItem(this.userKey, JSON.stringify(user));
+        if (refreshToken) {
+            localStorage.setItem(this.refreshTokenKey, refreshToken);
+        }
     }
 
     /**
@@ -75,6 +89,7 @@ class AuthService {
     clearAuth() {
         localStorage.removeItem(this.tokenKey);
         localStorage.removeItem(this.userKey);
+        localStorage.removeItem(this.refreshTokenKey);
     }
 
     /**
@@ -86,6 +101,65 @@ class AuthService {
         return token ? { Authorization: `Bearer ${token}` } : {};
     }
 
+    /**
+     * Tukar refresh token dengan access token baru.
+     * Request refresh yang bersamaan digabung menjadi satu.
+     * @returns {Promise<boolean>} - true jika berhasil
+     */
+    refresh() {
+        const refreshToken = this.getRefreshToken();
+        if (!refreshToken) {
+            return Promise.resolve(false);
+        }
+
+        if (!this.refreshing) {
+            this.refreshing = fetch(API_ENDPOINTS.AUTH_REFRESH, {
+                method: "POST",
+                headers: { "Content-Type": "application/json" },
+                body: JSON.stringify({ refresh_token: refreshToken }),
+            })
+                .then(async (response) => {
+                    if (!response.ok) {
+                        this.clearAuth();
+                        return false;
+                    }
+                    const data = await response.json();
+                    this.setAuth(data.token, data.user, data.refresh_token);
+                    return true;
+                })
+                .catch(() => false)
+                .finally(() => {
+                    this.refreshing = null;
+                });
+        }
+        return this.refreshing;
+    }
+
+    /**
+     * fetch dengan header Authorization. Jika access token sudah habis
+     * (401), token di-refresh lalu request diulang sekali.
+     * @param {string} url - URL request
+     * @param {Object} [options] - Opsi fetch
+     * @returns {Promise<Response>}
+     */
+    async fetch(url, options = {}) {
+        const send = () =>
+            fetch(url, {
+                ...options,
+                headers: { ...options.headers, ...this.getAuthHeaders() },
+            });
+
+        const response = await send();
+        if (response.status !== 401 || !this.getRefreshToken()) {
+            return response;
+        }
+
+        if (await this.refresh()) {
+            return send();
+        }
+        return response;
+    }
+
     /**
      * Login user
      * @param {string} email - Email user
@@ -106,7 +180,7 @@ class AuthService {
         }
 
         const data = await response.json();
-        this.setAuth(data.token, data.user);
+        this.setAuth(data.token, data.user, data.refresh_token);
         return data;
     }
 
@@ -131,7 +205,7 @@ class AuthService {
         }
 
         const data = await response.json();
-        this.setAuth(data.token, data.user);
+        this.setAuth(data.token, data.user, data.refresh_token);
         return data;
     }
 
@@ -141,9 +215,7 @@ class AuthService {
      * @throws {Error} - Jika session expired
      */
     async getMe() {
-        const response = await fetch(API_ENDPOINTS.AUTH_ME, {
-            headers: this.getAuthHeaders(),
-        });
+        const response = await this.fetch(API_ENDPOINTS.AUTH_ME);
 
         if (!response.ok) {
             this.clearAuth();
@@ -154,9 +226,25 @@ class AuthService {
     }
 
     /**
-     * Logout user
+     * Logout user. Token dicabut di server lalu dihapus dari localStorage.
+     * @param {boolean} [all=false] - Logout dari semua perangkat
      */
-    logout() {
+    async logout(all = false) {
+        try {
+            await fetch(API_ENDPOINTS.AUTH_LOGOUT, {
+                method: "POST",
+                headers: {
+                    "Content-Type": "application/json",
+                    ...this.getAuthHeaders(),
+                },
+                body: JSON.stringify({
+                    refresh_token: this.getRefreshToken() || "",
+                    all,
+                }),
+            });
+        } catch {
+            // Tetap logout lokal walaupun server tidak bisa dihubungi
+        }
         this.clearAuth();
         window.location.hash = "/";
     }
@@ -16,21 +16,46 @@
  */
 
 import { API_ENDPOINTS } from "../config";
+import authService from "./authService";
 
 class DocumentService {
   /**
-   * Ambil semua dokumen
-   * @returns {Promise<Array>} - Array of documents
+   * Ambil satu halaman dokumen
+   * @param {Object} params - page, limit, sort, order, status, jenis_file, penulis, from, to
+   * @returns {Promise<Object>} - { data, total, page, limit, total_pages }
    * @throws {Error} - Jika gagal mengambil data
    */
-  async getAll() {
-    const response = await fetch(API_ENDPOINTS.DOCUMENTS);
+  async getPage(params = {}) {
+    const query = new URLSearchParams(params).toString();
+    const url = query ? `${API_ENDPOINTS.DOCUMENTS}?${query}` : API_ENDPOINTS.DOCUMENTS;
+    const response = await authService.fetch(url, {
+      headers: authService.getAuthHeaders(),
+    });
     if (!response.ok) {
       throw new Error("Gagal mengambil data dokumen");
     }
     return response.json();
   }
 
+  /**
+   * Ambil semua dokumen (semua halaman)
+   * @param {Object} params - Filter yang sama dengan getPage
+   * @returns {Promise<Array>} - Array of documents
+   * @throws {Error} - Jika gagal mengambil data
+   */
+  async getAll(params = {}) {
+    const documents = [];
+    let page = 1;
+    let totalPages = 1;
+    do {
+      const result = await this.getPage({ ...params, page, limit: 100 });
+      documents.push(...result.data);
+      totalPages = result.total_pages;
+      page++;
+    } while (page <= totalPages);
+    return documents;
+  }
+
   /**
    * Ambil dokumen berdasarkan ID
    * @param {string} id - Document ID
@@ -38,7 +63,9 @@ class DocumentService {
    * @throws {Error} - Jika dokumen tidak ditemukan
    */
   async getById(id) {
-    const response = await fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id));
+    const response = await authService.fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id), {
+      headers: authService.getAuthHeaders(),
+    });
     if (!response.ok) {
       throw new Error("Gagal mengambil data dokumen");
     }
@@ -52,8 +79,9 @@ class DocumentService {
    * @throws {Error} - Jika gagal membuat dokumen
    */
   async create(formData) {
-    const response = await fetch(API_ENDPOINTS.DOCUMENTS, {
+    const response = await authService.fetch(API_ENDPOINTS.DOCUMENTS, {
       method: "POST",
+      headers: authService.getAuthHeaders(),
       body: formData,
     });
     if (!response.ok) {
@@ -70,8 +98,9 @@ class DocumentService {
    * @throws {Error} - Jika gagal mengupdate
    */
   async update(id, formData) {
-    const response = await fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id), {
+    const response = await authService.fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id), {
       method: "PUT",
+      headers: authService.getAuthHeaders(),
       body: formData,
     });
     if (!response.ok) {
@@ -87,8 +116,9 @@ class DocumentService {
    * @throws {Error} - Jika gagal menghapus
    */
   async delete(id) {
-    const response = await fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id), {
+    const response = await authService.fetch(API_ENDPOINTS.DOCUMENT_BY_ID(id), {
       method: "DELETE",
+      headers: authService.getAuthHeaders(),
     });
     if (!response.ok) {
       throw new Error("Gagal menghapus dokumen");
@@ -111,7 +141,9 @@ class DocumentService {
    * @returns {Promise<Array>} - Array of page filenames
    */
   async getPages(id) {
-    const response = await fetch(API_ENDPOINTS.DOCUMENT_PAGES(id));
+    const response = await authService.fetch(API_ENDPOINTS.DOCUMENT_PAGES(id), {
+      headers: authService.getAuthHeaders(),
+    });
     if (!response.ok) {
       throw new Error("Gagal mengambil halaman dokumen");
     }
@@ -4,7 +4,7 @@ const API_BASE = "http://localhost:8080";
 
 class UserService {
     async getUsers() {
-        const response = await fetch(`${API_BASE}/api/users`, {
+        const response = await authService.fetch(`${API_BASE}/api/users`, {
             headers: authService.getAuthHeaders(),
         });
 
@@ -17,7 +17,7 @@ class UserService {
     }
 
     async getUserById(id) {
-        const response = await fetch(`${API_BASE}/api/users/${id}`, {
+        const response = await authService.fetch(`${API_BASE}/api/users/${id}`, {
             headers: authService.getAuthHeaders(),
         });
 
@@ -30,7 +30,7 @@ class UserService {
     }
 
     async createUser(userData) {
-        const response = await fetch(`${API_BASE}/api/users`, {
+        const response = await authService.fetch(`${API_BASE}/api/users`, {
             method: "POST",
             headers: {
                 "Content-Type": "application/json",
@@ -48,7 +48,7 @@ class UserService {
     }
 
     async updateUser(id, userData) {
-        const response = await fetch(`${API_BASE}/api/users/${id}`, {
+        const response = await authService.fetch(`${API_BASE}/api/users/${id}`, {
             method: "PUT",
             headers: {
                 "Content-Type": "application/json",
@@ -66,7 +66,7 @@ class UserService {
     }
 
     async deleteUser(id) {
-        const response = await fetch(`${API_BASE}/api/users/${id}`, {
+        const response = await authService.fetch(`${API_BASE}/api/users/${id}`, {
             method: "DELETE",
             headers: authService.getAuthHeaders(),
         });